@@ -0,0 +1,80 @@
+// Command migrate applies or rolls back the embedded schema migrations.
+//
+//	migrate [-url postgres://...] up
+//	migrate [-url postgres://...] down [-steps N]
+//	migrate [-url postgres://...] status
+//
+// The URL defaults to the POSTGRES_URL environment variable.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmrtk/db-fd-model/m_migrate"
+)
+
+func main() {
+	url := flag.String("url", os.Getenv("POSTGRES_URL"), "PostgreSQL URL")
+	steps := flag.Int("steps", 1, "number of migrations to roll back with down")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] up|down|status\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *url == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, *url, flag.Arg(0), *steps); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, url string, command string, steps int) error {
+	db, err := pgxpool.New(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch command {
+	case "up":
+		applied, err := m_migrate.Up(ctx, db)
+		for _, version := range applied {
+			fmt.Printf("applied %d\n", version)
+		}
+		return err
+	case "down":
+		reverted, err := m_migrate.Down(ctx, db, steps)
+		for _, version := range reverted {
+			fmt.Printf("reverted %d\n", version)
+		}
+		return err
+	case "status":
+		statuses, err := m_migrate.GetStatus(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d %-30s %s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
@@ -0,0 +1,134 @@
+package db_fd_model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmrtk/db-fd-model/m_audit"
+	"github.com/rsmrtk/db-fd-model/m_currency_rate"
+	"github.com/rsmrtk/db-fd-model/m_expense"
+	"github.com/rsmrtk/db-fd-model/m_income"
+	"github.com/rsmrtk/db-fd-model/m_options"
+)
+
+// Schema objects that must exist for the facades to work.
+var (
+	expectedTables = []string{m_income.Table, m_expense.Table, m_currency_rate.Table, m_audit.Table}
+	expectedTypes  = []string{"expense_type_enum", "income_type_enum"}
+)
+
+type PoolStats struct {
+	Acquired     int32         `json:"acquired"`
+	Idle         int32         `json:"idle"`
+	Total        int32         `json:"total"`
+	Max          int32         `json:"max"`
+	WaitCount    int64         `json:"wait_count"`
+	WaitDuration time.Duration `json:"wait_duration"`
+}
+
+type ReplicaHealth struct {
+	Index   int           `json:"index"`
+	OK      bool          `json:"ok"`
+	Latency time.Duration `json:"latency"`
+	Lag     time.Duration `json:"lag"`
+	Pool    PoolStats     `json:"pool"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// HealthReport is the result of Model.Health. Live is true when the primary
+// answers; Ready additionally requires the expected tables and types.
+type HealthReport struct {
+	Live          bool            `json:"live"`
+	Ready         bool            `json:"ready"`
+	Latency       time.Duration   `json:"latency"`
+	ServerVersion string          `json:"server_version,omitempty"`
+	Pool          PoolStats       `json:"pool"`
+	Replicas      []ReplicaHealth `json:"replicas,omitempty"`
+	Tables        map[string]bool `json:"tables"`
+	Types         map[string]bool `json:"types"`
+	Errors        []string        `json:"errors,omitempty"`
+	CheckedAt     time.Time       `json:"checked_at"`
+}
+
+// Health checks the primary, the replicas and the schema. It never returns
+// an error: failures are reported in HealthReport.Errors and reflected in
+// Live and Ready, so the report can back liveness and readiness probes.
+func (m *Model) Health(ctx context.Context) *HealthReport {
+	report := &HealthReport{
+		Pool:      poolStats(m.DB),
+		Tables:    make(map[string]bool, len(expectedTables)),
+		Types:     make(map[string]bool, len(expectedTypes)),
+		CheckedAt: time.Now(),
+	}
+
+	latency, err := roundTrip(ctx, m.DB)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("primary: %v", err))
+		return report
+	}
+	report.Live = true
+	report.Latency = latency
+
+	if err := m.DB.QueryRow(ctx, "SHOW server_version").Scan(&report.ServerVersion); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("server_version: %v", err))
+	}
+
+	ready := true
+	for _, table := range expectedTables {
+		var exists bool
+		err := m.DB.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("table %s: %v", table, err))
+		}
+		report.Tables[table] = exists
+		ready = ready && exists
+	}
+	for _, typ := range expectedTypes {
+		var exists bool
+		err := m.DB.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_type WHERE typname = $1)", typ).Scan(&exists)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("type %s: %v", typ, err))
+		}
+		report.Types[typ] = exists
+		ready = ready && exists
+	}
+	report.Ready = ready
+
+	for i, replica := range m.replicas {
+		rh := ReplicaHealth{Index: i, Pool: poolStats(replica)}
+		rh.Latency, err = roundTrip(ctx, replica)
+		if err == nil {
+			rh.Lag, err = m_options.ReplicaLag(ctx, replica)
+		}
+		if err != nil {
+			rh.Error = err.Error()
+		} else {
+			rh.OK = true
+		}
+		report.Replicas = append(report.Replicas, rh)
+	}
+
+	return report
+}
+
+func roundTrip(ctx context.Context, db *pgxpool.Pool) (time.Duration, error) {
+	start := time.Now()
+	if err := ping(ctx, db); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+func poolStats(db *pgxpool.Pool) PoolStats {
+	stat := db.Stat()
+	return PoolStats{
+		Acquired:     stat.AcquiredConns(),
+		Idle:         stat.IdleConns(),
+		Total:        stat.TotalConns(),
+		Max:          stat.MaxConns(),
+		WaitCount:    stat.EmptyAcquireCount(),
+		WaitDuration: stat.EmptyAcquireWaitTime(),
+	}
+}
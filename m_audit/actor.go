@@ -0,0 +1,92 @@
+package m_audit
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rsmrtk/db-fd-model/m_options"
+)
+
+// Setting is the PostgreSQL setting the audit trigger reads the actor from.
+const Setting = "app.actor"
+
+type actorKey struct{}
+
+// WithActor returns a context whose writes are recorded in the audit log as
+// made by actor, e.g. a user id or a service name.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored by WithActor, if any.
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok && actor != ""
+}
+
+// Run calls write with the actor from ctx set for the audit trigger. On a
+// pool or connection it wraps write in a transaction, so the actor applies
+// to exactly this change; inside a transaction it sets the actor for the
+// duration of write and then restores the previous one, also when write
+// fails. write receives a context without the actor, so facade methods
+// called from it do not set it again. Without an actor Run calls write
+// directly on ex.
+func Run(
+	ctx context.Context,
+	ex m_options.Executor,
+	write func(ctx context.Context, ex m_options.Executor) error,
+) error {
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		return write(ctx, ex)
+	}
+	ctx = WithActor(ctx, "")
+
+	if _, inTx := ex.(pgx.Tx); inTx {
+		return runInTx(ctx, ex, actor, write)
+	}
+
+	tx, err := m_options.Begin(ctx, ex)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT set_config($1, $2, true)", Setting, actor); err != nil {
+		return err
+	}
+	if err := write(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// runInTx sets actor on the transaction tx for the duration of write. The
+// previous actor is restored even if write fails, so a caller that handles
+// the error and goes on writing in tx is not attributed to actor. If write
+// aborted tx, the restore fails too and only write's error is returned.
+func runInTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	actor string,
+	write func(ctx context.Context, ex m_options.Executor) error,
+) (err error) {
+	var previous *string
+	err = tx.QueryRow(ctx, "SELECT current_setting($1, true), set_config($1, $2, true)", Setting, actor).
+		Scan(&previous, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if previous == nil {
+			previous = new(string)
+		}
+		_, restoreErr := tx.Exec(context.WithoutCancel(ctx), "SELECT set_config($1, $2, true)", Setting, *previous)
+		if err == nil {
+			err = restoreErr
+		}
+	}()
+
+	return write(ctx, tx)
+}
@@ -0,0 +1,127 @@
+package m_audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/db-fd-model/m_schema"
+)
+
+// The audit log is written by the audit_row_change trigger on every insert,
+// update and delete of an audited table, in the same transaction as the
+// change. The facades set the actor from the context with Run.
+
+const (
+	Package = "m_audit"
+	Table   = "audit_log"
+	// Secondary indexes
+	IndexRecord = "idx_audit_log_record"
+)
+
+// Operation is the kind of change an Entry records.
+type Operation string
+
+const (
+	OperationInsert Operation = "INSERT"
+	OperationUpdate Operation = "UPDATE"
+	OperationDelete Operation = "DELETE"
+)
+
+// Entry is one change to one record. OldData and NewData hold the whole row,
+// keyed by column name, before and after the change; OldData is null on
+// insert and NewData on delete.
+type Entry struct {
+	AuditID   int64
+	TableName string
+	RecordID  string
+	Operation Operation
+	OldData   json.RawMessage
+	NewData   json.RawMessage
+	Actor     *string
+	ChangedAt time.Time
+}
+
+// Changed returns the columns whose value differs between OldData and
+// NewData, sorted by name. Every column is returned for an insert
+// or a delete.
+func (e *Entry) Changed() ([]string, error) {
+	var before, after map[string]json.RawMessage
+	if len(e.OldData) > 0 {
+		if err := json.Unmarshal(e.OldData, &before); err != nil {
+			return nil, fmt.Errorf("%s: failed to decode old_data: %w", Package, err)
+		}
+	}
+	if len(e.NewData) > 0 {
+		if err := json.Unmarshal(e.NewData, &after); err != nil {
+			return nil, fmt.Errorf("%s: failed to decode new_data: %w", Package, err)
+		}
+	}
+
+	var changed []string
+	for column, value := range after {
+		if old, ok := before[column]; !ok || string(old) != string(value) {
+			changed = append(changed, column)
+		}
+	}
+	for column := range before {
+		if _, ok := after[column]; !ok {
+			changed = append(changed, column)
+		}
+	}
+	slices.Sort(changed)
+	return changed, nil
+}
+
+// GetSchema describes how Entry maps the audit_log table, for drift checks.
+func GetSchema() m_schema.Table {
+	return m_schema.Table{
+		Name: Table,
+		Columns: []m_schema.Column{
+			{Name: "audit_id", GoType: "int64", PgTypes: []string{"bigint"}},
+			{Name: "table_name", GoType: "string", PgTypes: []string{"text"}},
+			{Name: "record_id", GoType: "string", PgTypes: []string{"text"}},
+			{Name: "operation", GoType: "Operation", PgTypes: []string{"text"}},
+			{Name: "old_data", GoType: "json.RawMessage", PgTypes: []string{"jsonb"}, Nullable: true},
+			{Name: "new_data", GoType: "json.RawMessage", PgTypes: []string{"jsonb"}, Nullable: true},
+			{Name: "actor", GoType: "*string", PgTypes: []string{"text"}, Nullable: true},
+			{Name: "changed_at", GoType: "time.Time", PgTypes: []string{"timestamp with time zone"}},
+		},
+	}
+}
+
+// History returns every change recorded for one record of table, oldest
+// first. The slice is empty when the record has no history.
+func History(
+	ctx context.Context,
+	ex m_options.Executor,
+	table string,
+	recordID string,
+) ([]*Entry, error) {
+	query := fmt.Sprintf(`SELECT audit_id, table_name, record_id, operation, old_data, new_data, actor, changed_at
+		FROM %s WHERE table_name = $1 AND record_id = $2 ORDER BY audit_id`, Table)
+
+	rows, err := ex.Query(ctx, query, table, recordID)
+	if err != nil {
+		return nil, m_errors.Map(err)
+	}
+	defer rows.Close()
+
+	res := make([]*Entry, 0)
+	for rows.Next() {
+		var e Entry
+		err := rows.Scan(&e.AuditID, &e.TableName, &e.RecordID, &e.Operation, &e.OldData, &e.NewData, &e.Actor, &e.ChangedAt)
+		if err != nil {
+			return nil, m_errors.Map(err)
+		}
+		res = append(res, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, m_errors.Map(err)
+	}
+	return res, nil
+}
@@ -0,0 +1,55 @@
+-- PostgreSQL table definition for audit_log
+-- Kept in sync with m_migrate/migrations; apply the schema with Model.Migrate or cmd/migrate.
+CREATE TABLE audit_log
+(
+    audit_id   BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    table_name TEXT NOT NULL,
+    record_id  TEXT NOT NULL,
+    operation  TEXT NOT NULL CHECK (operation IN ('INSERT', 'UPDATE', 'DELETE')),
+    old_data   JSONB,
+    new_data   JSONB,
+    actor      TEXT,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- History of a single record, oldest first
+CREATE INDEX idx_audit_log_record ON audit_log(table_name, record_id, audit_id);
+
+COMMENT ON TABLE audit_log IS 'Old and new values of every insert, update and delete on audited tables';
+COMMENT ON COLUMN audit_log.record_id IS 'Primary key of the changed record, as text';
+COMMENT ON COLUMN audit_log.old_data IS 'Record before the change, NULL on INSERT';
+COMMENT ON COLUMN audit_log.new_data IS 'Record after the change, NULL on DELETE';
+COMMENT ON COLUMN audit_log.actor IS 'Value of the app.actor setting of the transaction, set by m_audit.WithActor';
+
+-- TG_ARGV[0] names the primary key column of the audited table
+CREATE FUNCTION audit_row_change() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_row := to_jsonb(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        new_row := to_jsonb(NEW);
+    END IF;
+    IF old_row = new_row THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO audit_log (table_name, record_id, operation, old_data, new_data, actor)
+    VALUES (TG_TABLE_NAME, COALESCE(new_row, old_row) ->> TG_ARGV[0], TG_OP, old_row, new_row,
+            NULLIF(current_setting('app.actor', true), ''));
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER incomes_audit
+    AFTER INSERT OR UPDATE OR DELETE ON incomes
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('income_id');
+
+CREATE TRIGGER expenses_audit
+    AFTER INSERT OR UPDATE OR DELETE ON expenses
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('expense_id');
@@ -0,0 +1,704 @@
+package m_currency_rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/db-fd-model/m_schema"
+	"github.com/rsmrtk/db-fd-model/m_types"
+	"github.com/rsmrtk/db-fd-model/sql_builder"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+const (
+	Package = "m_currency_rate"
+	Table   = "currency_rates"
+	// Secondary indexes
+	IndexPairDate = "idx_currency_rates_pair_date"
+)
+
+// ErrRateNotFound is returned when no rate is known on or before a date. It
+// matches m_errors.ErrNotFound as well.
+var ErrRateNotFound = fmt.Errorf("%w: currency rate", m_errors.ErrNotFound)
+
+type Facade struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+	ex  m_options.Executor
+	//
+	router *m_options.Router
+}
+
+func New(o *m_options.Options) *Facade {
+	return &Facade{
+		log: o.Log,
+		db:  o.DB,
+		//
+		router: o.Router,
+	}
+}
+
+func (f *Facade) logError(functionName string, msg string, h logger.H) {
+	f.log.Error(fmt.Sprintf("[%s.%s - %s] %s", Package, functionName, Table, msg), h)
+}
+
+// WithExecutor returns a copy of the facade bound to ex, e.g. a pgx.Tx
+// shared with other facades.
+func (f *Facade) WithExecutor(ex m_options.Executor) *Facade {
+	bound := *f
+	bound.ex = ex
+	return &bound
+}
+
+// executor returns the bound executor, the executor carried by ctx, or the
+// shared pool.
+func (f *Facade) executor(ctx context.Context) m_options.Executor {
+	if f.ex != nil {
+		return f.ex
+	}
+	if ex, ok := m_options.ExecutorFromContext(ctx); ok {
+		return ex
+	}
+	return f.db
+}
+
+// reader is like executor, but sends reads to a replica when replicas are
+// configured and ctx was not marked with m_options.WithPrimary.
+func (f *Facade) reader(ctx context.Context) m_options.Executor {
+	if f.ex != nil {
+		return f.ex
+	}
+	if ex, ok := m_options.ExecutorFromContext(ctx); ok {
+		return ex
+	}
+	if f.router != nil && !m_options.UsePrimary(ctx) {
+		return f.router.Reader()
+	}
+	return f.db
+}
+
+// Data is one daily rate: 1 BaseCurrency = Rate QuoteCurrency on RateDate.
+type Data struct {
+	RateDate      time.Time
+	BaseCurrency  m_types.Currency
+	QuoteCurrency m_types.Currency
+	Rate          *m_types.Decimal
+	Source        *string
+	CreatedAt     *time.Time
+}
+
+func (data *Data) Map() map[string]any {
+	out := make(map[string]any, len(allFieldsList))
+	out[string(RateDate)] = data.RateDate
+	out[string(BaseCurrency)] = data.BaseCurrency
+	out[string(QuoteCurrency)] = data.QuoteCurrency
+	out[string(Rate)] = data.Rate
+	out[string(Source)] = data.Source
+	out[string(CreatedAt)] = data.CreatedAt
+	return out
+}
+
+type Field string
+
+const (
+	RateDate      Field = "rate_date"
+	BaseCurrency  Field = "base_currency"
+	QuoteCurrency Field = "quote_currency"
+	Rate          Field = "rate"
+	Source        Field = "source"
+	CreatedAt     Field = "created_at"
+)
+
+func GetAllFields() []Field {
+	return []Field{
+		RateDate,
+		BaseCurrency,
+		QuoteCurrency,
+		Rate,
+		Source,
+		CreatedAt,
+	}
+}
+
+var allFieldsList = GetAllFields()
+
+func (f Field) String() string {
+	return string(f)
+}
+
+var fieldsMap = map[Field]func(data *Data) interface{}{
+	RateDate:      func(data *Data) interface{} { return &data.RateDate },
+	BaseCurrency:  func(data *Data) interface{} { return &data.BaseCurrency },
+	QuoteCurrency: func(data *Data) interface{} { return &data.QuoteCurrency },
+	Rate:          func(data *Data) interface{} { return &data.Rate },
+	Source:        func(data *Data) interface{} { return &data.Source },
+	CreatedAt:     func(data *Data) interface{} { return &data.CreatedAt },
+}
+
+func (data *Data) fieldPtrs(fields []Field) []interface{} {
+	ptrs := make([]interface{}, len(fields))
+
+	for i, field := range fields {
+		ptrs[i] = fieldsMap[field](data)
+	}
+	return ptrs
+}
+
+type PrimaryKey struct {
+	RateDate      time.Time
+	BaseCurrency  m_types.Currency
+	QuoteCurrency m_types.Currency
+}
+
+func GetColumns() []string {
+	return []string{
+		RateDate.String(),
+		BaseCurrency.String(),
+		QuoteCurrency.String(),
+		Rate.String(),
+		Source.String(),
+		CreatedAt.String(),
+	}
+}
+
+var allStringFields = GetColumns()
+
+// GetSchema describes how Data maps the currency_rates table, for drift checks.
+func GetSchema() m_schema.Table {
+	return m_schema.Table{
+		Name: Table,
+		Columns: []m_schema.Column{
+			{Name: RateDate.String(), GoType: "time.Time", PgTypes: []string{"date"}},
+			{Name: BaseCurrency.String(), GoType: "m_types.Currency", PgTypes: []string{"character"}},
+			{Name: QuoteCurrency.String(), GoType: "m_types.Currency", PgTypes: []string{"character"}},
+			{Name: Rate.String(), GoType: "*m_types.Decimal", PgTypes: []string{"numeric"}, Nullable: true},
+			{Name: Source.String(), GoType: "*string", PgTypes: []string{"character varying", "text"}, Nullable: true},
+			{Name: CreatedAt.String(), GoType: "*time.Time", PgTypes: []string{"timestamp with time zone", "timestamp without time zone"}, Nullable: true},
+		},
+	}
+}
+
+func GetValues(data *Data) []interface{} {
+	return []interface{}{
+		data.RateDate,
+		data.BaseCurrency,
+		data.QuoteCurrency,
+		data.Rate,
+		data.Source,
+		data.CreatedAt,
+	}
+}
+
+type UpdateFields map[Field]interface{}
+
+func (uf UpdateFields) Map() map[string]any {
+	out := make(map[string]any, len(uf))
+	for k, v := range uf {
+		out[string(k)] = v
+	}
+	return out
+}
+
+type Op string
+
+const (
+	OpEq    Op = "="      // Equal
+	OpNe    Op = "!="     // Not Equal
+	OpIn    Op = "IN"     // In
+	OpLt    Op = "<"      // Less than
+	OpGt    Op = ">"      // Greater than
+	OpLe    Op = "<="     // Less than or equal
+	OpGe    Op = ">="     // Greater than or equal
+	OpIs    Op = "IS"     // Is (null)
+	OpIsNot Op = "IS NOT" // Is not (null)
+)
+
+type QueryParam struct {
+	Field    Field
+	Operator Op
+	Value    interface{}
+}
+
+func makeStringFields(fields []Field) []string {
+	stringFields := make([]string, len(fields))
+	for i, f := range fields {
+		stringFields[i] = string(f)
+	}
+
+	return stringFields
+}
+
+// nil selects all fields
+func SelectQuery(fields []Field) string {
+	var stringFields []string
+	if fields == nil || len(fields) == 0 {
+		stringFields = makeStringFields(allFieldsList)
+	} else {
+		stringFields = makeStringFields(fields)
+	}
+
+	queryString := fmt.Sprintf("SELECT %s FROM %s",
+		strings.Join(stringFields, ", "), Table)
+
+	return queryString
+}
+
+func ConstructWhereClause(queryParams []QueryParam) (whereClause string, params map[string]interface{}) {
+	whereClauses := make([]string, len(queryParams))
+	params = make(map[string]interface{}, len(queryParams))
+	builder := strings.Builder{}
+	for i, qp := range queryParams {
+		if (qp.Operator == OpIs || qp.Operator == OpIsNot) && qp.Value == nil {
+			whereClauses[i] = fmt.Sprintf("%s %s NULL", qp.Field, qp.Operator)
+			continue
+		}
+		// Number params by value, so IS NULL clauses do not leave gaps
+		builder.WriteString("param")
+		builder.WriteString(strconv.Itoa(len(params)))
+		paramName := builder.String()
+		builder.Reset()
+
+		// Construct param - PostgreSQL uses $N syntax
+		builder.WriteString("$")
+		builder.WriteString(strconv.Itoa(len(params) + 1))
+		param := builder.String()
+		builder.Reset()
+
+		if qp.Operator == OpIn {
+			// PostgreSQL uses = ANY($N) instead of IN UNNEST(@param)
+			builder.WriteString("= ANY(")
+			builder.WriteString(param)
+			builder.WriteString(")")
+			param = builder.String()
+			builder.Reset()
+		}
+
+		// Construct whereClause
+		builder.WriteString(string(qp.Field))
+		builder.WriteString(" ")
+		if qp.Operator != OpIn {
+			builder.WriteString(string(qp.Operator))
+			builder.WriteString(" ")
+		}
+		builder.WriteString(param)
+		whereClause := builder.String()
+		whereClauses[i] = whereClause
+		params[paramName] = qp.Value
+		builder.Reset()
+	}
+
+	return strings.Join(whereClauses, " AND "), params
+}
+
+// Conversion is the SQL that converts an amount column into the currency
+// bound to a query parameter, using the nearest rate on or before a date.
+type Conversion struct {
+	Join   string // LEFT JOIN LATERAL exposing the rate as cr.rate
+	Amount string // Converted amount, NULL when no rate is known
+	Rate   string // Rate used, 1 when the currencies match
+}
+
+// Convert builds a Conversion for amount held in currency on date, where all
+// three are SQL expressions qualified with their table name, and param is
+// the placeholder of the reporting currency, e.g. "$3". Rates stored in the
+// opposite direction are inverted.
+func Convert(amount, currency, date, param string) Conversion {
+	join := fmt.Sprintf(`LEFT JOIN LATERAL (
+		SELECT CASE WHEN r.%[4]s = %[1]s THEN r.%[6]s ELSE 1 / r.%[6]s END AS rate
+		FROM %[3]s r
+		WHERE r.%[7]s <= (%[2]s)::date
+			AND ((r.%[4]s = %[1]s AND r.%[5]s = %[8]s) OR (r.%[4]s = %[8]s AND r.%[5]s = %[1]s))
+		ORDER BY r.%[7]s DESC
+		LIMIT 1
+	) cr ON true`, currency, date, Table, BaseCurrency, QuoteCurrency, Rate, RateDate, param)
+
+	rate := fmt.Sprintf("CASE WHEN %s = %s THEN 1 ELSE cr.rate END", currency, param)
+	return Conversion{
+		Join:   join,
+		Amount: fmt.Sprintf("%s * (%s)", amount, rate),
+		Rate:   rate,
+	}
+}
+
+func (f *Facade) CreateOrUpdate(
+	ctx context.Context,
+	data *Data,
+) error {
+	return f.CreateOrUpdateTx(ctx, f.executor(ctx), data)
+}
+
+func (f *Facade) CreateOrUpdateTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	data *Data,
+) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, COALESCE($6, CURRENT_TIMESTAMP))
+		ON CONFLICT (%s, %s, %s) DO UPDATE SET
+			%s = EXCLUDED.%s,
+			%s = EXCLUDED.%s`,
+		Table, strings.Join(allStringFields, ", "),
+		RateDate, BaseCurrency, QuoteCurrency,
+		Rate, Rate,
+		Source, Source,
+	)
+
+	_, err := tx.Exec(ctx, query, GetValues(data)...)
+	if err != nil {
+		f.logError("CreateOrUpdate", "Failed to execute", logger.H{
+			"error": err,
+			"data":  data,
+		})
+		return m_errors.Map(err)
+	}
+
+	return nil
+}
+
+// CreateOrUpdateMany upserts rates in one transaction, or in a savepoint
+// when the facade already runs inside one.
+func (f *Facade) CreateOrUpdateMany(
+	ctx context.Context,
+	rates []*Data,
+) error {
+	tx, err := m_options.Begin(ctx, f.executor(ctx))
+	if err != nil {
+		f.logError("CreateOrUpdateMany", "Failed to Begin transaction", logger.H{
+			"error": err,
+		})
+		return m_errors.Map(err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, data := range rates {
+		if err := f.CreateOrUpdateTx(ctx, tx, data); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		f.logError("CreateOrUpdateMany", "Failed to Commit transaction", logger.H{
+			"error": err,
+		})
+		return m_errors.Map(err)
+	}
+
+	return nil
+}
+
+func (f *Facade) Create(ctx context.Context, data *Data) error {
+	return f.CreateTx(ctx, f.executor(ctx), data)
+}
+
+func (f *Facade) CreateTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	data *Data,
+) error {
+	query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, COALESCE($6, CURRENT_TIMESTAMP))`,
+		Table, strings.Join(allStringFields, ", "))
+
+	_, err := tx.Exec(ctx, query, GetValues(data)...)
+	if err != nil {
+		f.logError("CreateTx", "Failed to execute", logger.H{
+			"error": err,
+			"data":  data,
+		})
+		return m_errors.Map(err)
+	}
+	return nil
+}
+
+func (f *Facade) Find(
+	ctx context.Context,
+	pk PrimaryKey,
+	fields []Field,
+) (*Data, error) {
+	return f.find(ctx, "Find", f.reader(ctx), pk, fields)
+}
+
+func (f *Facade) FindTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	pk PrimaryKey,
+	fields []Field,
+) (*Data, error) {
+	return f.find(ctx, "FindTx", tx, pk, fields)
+}
+
+func (f *Facade) find(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	pk PrimaryKey,
+	fields []Field,
+) (*Data, error) {
+	if len(fields) == 0 {
+		fields = allFieldsList
+	}
+
+	queryString := SelectQuery(fields)
+	queryString += fmt.Sprintf(` WHERE %s = $1 AND %s = $2 AND %s = $3`,
+		RateDate, BaseCurrency, QuoteCurrency)
+
+	var data Data
+	err := ex.QueryRow(ctx, queryString, pk.RateDate, pk.BaseCurrency, pk.QuoteCurrency).
+		Scan(data.fieldPtrs(fields)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, m_errors.Map(err)
+		}
+		f.logError(functionName, "Failed to Scan", logger.H{
+			"error":      err,
+			"primaryKey": pk,
+			"fields":     fields,
+		})
+		return nil, m_errors.Map(err)
+	}
+
+	return &data, nil
+}
+
+// Nearest returns the rate for the pair on date, or on the closest earlier
+// date when none was published that day (weekends, holidays). It returns
+// ErrRateNotFound when no earlier rate exists.
+func (f *Facade) Nearest(
+	ctx context.Context,
+	base m_types.Currency,
+	quote m_types.Currency,
+	date time.Time,
+) (*Data, error) {
+	return f.nearest(ctx, "Nearest", f.reader(ctx), base, quote, date)
+}
+
+func (f *Facade) NearestTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	base m_types.Currency,
+	quote m_types.Currency,
+	date time.Time,
+) (*Data, error) {
+	return f.nearest(ctx, "NearestTx", tx, base, quote, date)
+}
+
+func (f *Facade) nearest(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	base m_types.Currency,
+	quote m_types.Currency,
+	date time.Time,
+) (*Data, error) {
+	queryString := SelectQuery(allFieldsList)
+	queryString += fmt.Sprintf(` WHERE %s = $1 AND %s = $2 AND %s <= $3 ORDER BY %s DESC LIMIT 1`,
+		BaseCurrency, QuoteCurrency, RateDate, RateDate)
+
+	var data Data
+	err := ex.QueryRow(ctx, queryString, base, quote, date).Scan(data.fieldPtrs(allFieldsList)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s/%s on or before %s",
+				ErrRateNotFound, base, quote, date.Format(time.DateOnly))
+		}
+		f.logError(functionName, "Failed to Scan", logger.H{
+			"error": err,
+			"base":  base,
+			"quote": quote,
+			"date":  date,
+		})
+		return nil, m_errors.Map(err)
+	}
+
+	return &data, nil
+}
+
+func (f *Facade) Get(
+	ctx context.Context,
+	queryParams []QueryParam,
+	fields []Field,
+) ([]*Data, error) {
+	return f.get(ctx, "Get", f.reader(ctx), queryParams, fields)
+}
+
+func (f *Facade) GetTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	queryParams []QueryParam,
+	fields []Field,
+) ([]*Data, error) {
+	return f.get(ctx, "GetTx", tx, queryParams, fields)
+}
+
+func (f *Facade) get(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	queryParams []QueryParam,
+	fields []Field,
+) ([]*Data, error) {
+	res := make([]*Data, 0)
+	err := f.getIter(ctx, functionName, ex, queryParams, fields, func(data *Data) {
+		res = append(res, data)
+	})
+	if err != nil {
+		return nil, m_errors.Map(err)
+	}
+
+	return res, nil
+}
+
+func (f *Facade) GetIter(
+	ctx context.Context,
+	queryParams []QueryParam,
+	fields []Field,
+	callback func(*Data),
+) error {
+	return f.getIter(ctx, "GetIter", f.reader(ctx), queryParams, fields, callback)
+}
+
+func (f *Facade) getIter(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	queryParams []QueryParam,
+	fields []Field,
+	callback func(*Data),
+) error {
+	queryString := SelectQuery(fields)
+	whereClauses, params := ConstructWhereClause(queryParams)
+	if len(queryParams) > 0 {
+		queryString += " WHERE " + whereClauses
+	}
+
+	// Convert map params to ordered slice
+	args := make([]interface{}, len(params))
+	for i := 0; i < len(params); i++ {
+		paramName := fmt.Sprintf("param%d", i)
+		args[i] = params[paramName]
+	}
+
+	if len(fields) == 0 {
+		fields = allFieldsList
+	}
+
+	rows, err := ex.Query(ctx, queryString, args...)
+	if err != nil {
+		f.logError(functionName, "Failed to query", logger.H{
+			"error":        err,
+			"query_params": queryParams,
+			"fields":       fields,
+		})
+		return m_errors.Map(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data Data
+		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
+			f.logError(functionName, "Failed to Scan", logger.H{
+				"error":        err,
+				"query_params": queryParams,
+				"fields":       fields,
+			})
+			return m_errors.Map(err)
+		}
+		callback(&data)
+	}
+
+	return m_errors.Map(rows.Err())
+}
+
+func (f *Facade) List(
+	ctx context.Context,
+	queryParams []QueryParam,
+) ([]*Data, error) {
+	return f.Get(ctx, queryParams, allFieldsList)
+}
+
+func (f *Facade) GetByBuilder(ctx context.Context, builder *sql_builder.Builder[Field]) ([]*Data, error) {
+	return f.getByBuilder(ctx, "GetByBuilder", f.reader(ctx), builder)
+}
+
+func (f *Facade) GetByBuilderTx(ctx context.Context, tx m_options.Executor, builder *sql_builder.Builder[Field]) ([]*Data, error) {
+	return f.getByBuilder(ctx, "GetByBuilderTx", tx, builder)
+}
+
+func (f *Facade) getByBuilder(ctx context.Context, functionName string, ex m_options.Executor, builder *sql_builder.Builder[Field]) ([]*Data, error) {
+	if builder == nil {
+		return nil, fmt.Errorf("builder cannot be nil")
+	}
+	queryStr := builder.StringPostgres()
+	queryArgs := builder.ArgsPostgres()
+	fields := builder.Fields()
+	if len(fields) == 0 {
+		fields = allFieldsList
+	}
+
+	rows, err := ex.Query(ctx, queryStr, queryArgs...)
+	if err != nil {
+		f.logError(functionName, "Failed to query", logger.H{
+			"error":  err,
+			"fields": fields,
+		})
+		return nil, m_errors.Map(err)
+	}
+	defer rows.Close()
+
+	res := make([]*Data, 0)
+	for rows.Next() {
+		var data Data
+		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
+			f.logError(functionName, "Failed to Scan", logger.H{
+				"error":  err,
+				"fields": fields,
+			})
+			return nil, m_errors.Map(err)
+		}
+		res = append(res, &data)
+	}
+
+	return res, m_errors.Map(rows.Err())
+}
+
+func (f *Facade) InitBuilder() *sql_builder.Builder[Field] {
+	b := sql_builder.New[Field]("")
+	b.Select(allFieldsList...).From(Table)
+	return b
+}
+
+// Delete removes the rate with pk and returns the number of rows affected,
+// 0 when no rate matched.
+func (f *Facade) Delete(
+	ctx context.Context,
+	pk PrimaryKey,
+) (int64, error) {
+	return f.DeleteTx(ctx, f.executor(ctx), pk)
+}
+
+func (f *Facade) DeleteTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	pk PrimaryKey,
+) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND %s = $2 AND %s = $3`,
+		Table, RateDate, BaseCurrency, QuoteCurrency)
+
+	tag, err := tx.Exec(ctx, query, pk.RateDate, pk.BaseCurrency, pk.QuoteCurrency)
+	if err != nil {
+		f.logError("Delete", "Failed to execute", logger.H{
+			"error":      err,
+			"primaryKey": pk,
+		})
+		return 0, m_errors.Map(err)
+	}
+
+	return tag.RowsAffected(), nil
+}
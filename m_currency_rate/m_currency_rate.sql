@@ -0,0 +1,23 @@
+-- PostgreSQL table definition for currency_rates
+-- Kept in sync with m_migrate/migrations; apply the schema with Model.Migrate or cmd/migrate.
+CREATE TABLE currency_rates
+(
+    rate_date      DATE            NOT NULL,
+    base_currency  CHAR(3)         NOT NULL CHECK (base_currency ~ '^[A-Z]{3}$'),
+    quote_currency CHAR(3)         NOT NULL CHECK (quote_currency ~ '^[A-Z]{3}$'),
+    rate           NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    source         VARCHAR(32),
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (rate_date, base_currency, quote_currency)
+);
+
+-- Nearest previous rate lookups scan a pair backwards from a date
+CREATE INDEX idx_currency_rates_pair_date ON currency_rates(base_currency, quote_currency, rate_date DESC);
+
+COMMENT ON TABLE currency_rates IS 'Daily exchange rates: 1 base_currency = rate quote_currency';
+COMMENT ON COLUMN currency_rates.rate_date IS 'Date the rate is valid from';
+COMMENT ON COLUMN currency_rates.base_currency IS 'ISO 4217 currency being priced';
+COMMENT ON COLUMN currency_rates.quote_currency IS 'ISO 4217 currency the price is expressed in';
+COMMENT ON COLUMN currency_rates.rate IS 'Units of quote_currency per unit of base_currency';
+COMMENT ON COLUMN currency_rates.source IS 'Where the rate came from, e.g. ECB or NBU';
+COMMENT ON COLUMN currency_rates.created_at IS 'Record creation timestamp';
@@ -0,0 +1,141 @@
+package m_errors
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Sentinel errors returned (wrapped in *Error) by every facade. Match them
+// with errors.Is.
+var (
+	ErrNotFound      = errors.New("record not found")
+	ErrAlreadyExists = errors.New("record already exists")
+	ErrConstraint    = errors.New("constraint violation")
+	ErrInvalidEnum   = errors.New("invalid enum value")
+	ErrSerialization = errors.New("serialization failure")
+	ErrTimeout       = errors.New("timeout")
+	ErrValidation    = errors.New("validation failed")
+	ErrStaleVersion  = errors.New("stale version")
+)
+
+// Error is a database error classified by Kind. It keeps the SQLSTATE and,
+// when the server reports them, the table, column and constraint involved.
+type Error struct {
+	Kind       error
+	Code       string
+	Table      string
+	Column     string
+	Constraint string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	details := make([]string, 0, 4)
+	if e.Code != "" {
+		details = append(details, "SQLSTATE "+e.Code)
+	}
+	if e.Table != "" {
+		details = append(details, "table "+e.Table)
+	}
+	if e.Column != "" {
+		details = append(details, "column "+e.Column)
+	}
+	if e.Constraint != "" {
+		details = append(details, "constraint "+e.Constraint)
+	}
+
+	msg := e.Kind.Error()
+	if len(details) > 0 {
+		msg += " (" + strings.Join(details, ", ") + ")"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap exposes both the Kind sentinel and the driver error to errors.Is
+// and errors.As.
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// sqlStateError is implemented by *pgconn.PgError and *pq.Error.
+type sqlStateError interface {
+	SQLState() string
+}
+
+// Map converts pgx and lib/pq errors into *Error. Errors it does not
+// recognise, and errors that are already classified, are returned unchanged.
+func Map(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{Kind: ErrNotFound, Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		kind := kindOf(pgErr.Code)
+		if kind == nil {
+			return err
+		}
+		return &Error{
+			Kind:       kind,
+			Code:       pgErr.Code,
+			Table:      pgErr.TableName,
+			Column:     pgErr.ColumnName,
+			Constraint: pgErr.ConstraintName,
+			Err:        err,
+		}
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		if kind := kindOf(stateErr.SQLState()); kind != nil {
+			return &Error{Kind: kind, Code: stateErr.SQLState(), Err: err}
+		}
+		return err
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return &Error{Kind: ErrTimeout, Err: err}
+	}
+
+	return err
+}
+
+// kindOf maps a SQLSTATE code to a sentinel, or nil when it has none.
+func kindOf(code string) error {
+	switch code {
+	case "23505": // unique_violation
+		return ErrAlreadyExists
+	case "22P02": // invalid_text_representation, e.g. an unknown enum label
+		return ErrInvalidEnum
+	case "40001", "40P01": // serialization_failure, deadlock_detected
+		return ErrSerialization
+	case "57014", "55P03": // query_canceled (statement_timeout), lock_not_available
+		return ErrTimeout
+	}
+	if strings.HasPrefix(code, "23") { // integrity_constraint_violation class
+		return ErrConstraint
+	}
+	return nil
+}
+
+// IsRetryable reports whether the transaction that produced err may succeed
+// when run again.
+func IsRetryable(err error) bool {
+	return errors.Is(Map(err), ErrSerialization)
+}
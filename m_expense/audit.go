@@ -0,0 +1,64 @@
+package m_expense
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rsmrtk/db-fd-model/m_audit"
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// Every insert, update and delete of an expense is recorded in the audit log
+// by a trigger, in the same transaction. Writes go through exec, queryRow or
+// queryRows, which set the actor from the context with m_audit.Run.
+
+// exec runs a write with the actor from ctx set for the audit trigger.
+func (f *Facade) exec(
+	ctx context.Context,
+	ex m_options.Executor,
+	query string,
+	args ...interface{},
+) (pgconn.CommandTag, error) {
+	var tag pgconn.CommandTag
+	err := m_audit.Run(ctx, ex, func(ctx context.Context, ex m_options.Executor) error {
+		var err error
+		tag, err = ex.Exec(ctx, query, args...)
+		return err
+	})
+	return tag, err
+}
+
+// History returns every recorded change of the expense, oldest first,
+// including its deletion.
+func (f *Facade) History(
+	ctx context.Context,
+	pk PrimaryKey,
+) ([]*m_audit.Entry, error) {
+	return f.history(ctx, "History", f.reader(ctx), pk)
+}
+
+func (f *Facade) HistoryTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	pk PrimaryKey,
+) ([]*m_audit.Entry, error) {
+	return f.history(ctx, "HistoryTx", tx, pk)
+}
+
+func (f *Facade) history(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	pk PrimaryKey,
+) ([]*m_audit.Entry, error) {
+	res, err := m_audit.History(ctx, ex, Table, pk.ExpenseID.String())
+	if err != nil {
+		f.logError(functionName, "Failed to Query", logger.H{
+			"error": err, "primaryKey": pk,
+		})
+		return nil, m_errors.Map(err)
+	}
+	return res, nil
+}
@@ -0,0 +1,208 @@
+package m_expense
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rsmrtk/db-fd-model/m_currency_rate"
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/db-fd-model/m_types"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// defaultCurrency fills a missing currency with the column default, since an
+// explicit NULL would not fall back to it.
+func defaultCurrency(data *Data) {
+	if data.ExpenseCurrency == nil {
+		c := m_types.DefaultCurrency
+		data.ExpenseCurrency = &c
+	}
+}
+
+// ConvertedData is an expense with its amount in a reporting currency.
+// Converted is nil when the amount is NULL or no rate is known on or before
+// the expense date.
+type ConvertedData struct {
+	*Data
+	Currency  m_types.Currency
+	Converted *m_types.Money
+}
+
+// conversion converts expense_amount into the currency bound to param as of
+// expense_date, or created_at when the date is missing.
+func conversion(param string) m_currency_rate.Conversion {
+	return m_currency_rate.Convert(
+		Table+"."+ExpenseAmount.String(),
+		Table+"."+ExpenseCurrency.String(),
+		fmt.Sprintf("COALESCE(%s.%s, %s.%s)", Table, ExpenseDate, Table, CreatedAt),
+		param,
+	)
+}
+
+// GetConverted returns the expenses matching queryParams with their amounts
+// converted to currency, rounded to cents.
+func (f *Facade) GetConverted(
+	ctx context.Context,
+	queryParams []QueryParam,
+	fields []Field,
+	currency m_types.Currency,
+) ([]*ConvertedData, error) {
+	return f.getConverted(ctx, "GetConverted", f.reader(ctx), queryParams, fields, currency)
+}
+
+func (f *Facade) GetConvertedTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	queryParams []QueryParam,
+	fields []Field,
+	currency m_types.Currency,
+) ([]*ConvertedData, error) {
+	return f.getConverted(ctx, "GetConvertedTx", tx, queryParams, fields, currency)
+}
+
+func (f *Facade) getConverted(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	queryParams []QueryParam,
+	fields []Field,
+	currency m_types.Currency,
+) ([]*ConvertedData, error) {
+	queryParams = f.scoped(queryParams)
+
+	if len(fields) == 0 {
+		fields = allFieldsList
+	}
+
+	whereClause, args := ConstructWhereClause(queryParams)
+	args = append(args, currency)
+	conv := conversion(fmt.Sprintf("$%d", len(args)))
+
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = Table + "." + field.String()
+	}
+
+	query := fmt.Sprintf("SELECT %s, ROUND(%s, 2) FROM %s %s",
+		strings.Join(columns, ", "), conv.Amount, Table, conv.Join)
+	if len(queryParams) > 0 {
+		query += " WHERE " + whereClause
+	}
+
+	rows, err := ex.Query(ctx, query, args...)
+	if err != nil {
+		f.logError(functionName, "Failed to Query", logger.H{
+			"error":        err,
+			"query_params": queryParams,
+			"currency":     currency,
+		})
+		return nil, m_errors.Map(err)
+	}
+	defer rows.Close()
+
+	var res []*ConvertedData
+	for rows.Next() {
+		row := &ConvertedData{Data: &Data{}, Currency: currency}
+		if err := rows.Scan(append(row.fieldPtrs(fields), &row.Converted)...); err != nil {
+			f.logError(functionName, "Failed to Scan", logger.H{
+				"error":        err,
+				"query_params": queryParams,
+				"currency":     currency,
+			})
+			return nil, m_errors.Map(err)
+		}
+		res = append(res, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, m_errors.Map(err)
+	}
+
+	return res, nil
+}
+
+// TotalsByType sums the expenses matching queryParams per expense type, in
+// currency. It fails with m_currency_rate.ErrRateNotFound rather than return
+// a partial total when a rate is missing for any expense.
+func (f *Facade) TotalsByType(
+	ctx context.Context,
+	queryParams []QueryParam,
+	currency m_types.Currency,
+) (map[EnumType]m_types.Money, error) {
+	return f.totalsByType(ctx, "TotalsByType", f.reader(ctx), queryParams, currency)
+}
+
+func (f *Facade) TotalsByTypeTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	queryParams []QueryParam,
+	currency m_types.Currency,
+) (map[EnumType]m_types.Money, error) {
+	return f.totalsByType(ctx, "TotalsByTypeTx", tx, queryParams, currency)
+}
+
+func (f *Facade) totalsByType(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	queryParams []QueryParam,
+	currency m_types.Currency,
+) (map[EnumType]m_types.Money, error) {
+	queryParams = f.scoped(queryParams)
+
+	whereClause, args := ConstructWhereClause(queryParams)
+	args = append(args, currency)
+	conv := conversion(fmt.Sprintf("$%d", len(args)))
+
+	query := fmt.Sprintf(`
+		SELECT COALESCE(%[1]s.%[2]s::text, ''),
+			COALESCE(ROUND(SUM(%[3]s), 2), 0),
+			COUNT(*) FILTER (WHERE %[1]s.%[4]s IS NOT NULL AND %[3]s IS NULL)
+		FROM %[1]s %[5]s`,
+		Table, ExpenseType, conv.Amount, ExpenseAmount, conv.Join)
+	if len(queryParams) > 0 {
+		query += " WHERE " + whereClause
+	}
+	query += " GROUP BY 1"
+
+	rows, err := ex.Query(ctx, query, args...)
+	if err != nil {
+		f.logError(functionName, "Failed to Query", logger.H{
+			"error":        err,
+			"query_params": queryParams,
+			"currency":     currency,
+		})
+		return nil, m_errors.Map(err)
+	}
+	defer rows.Close()
+
+	totals := make(map[EnumType]m_types.Money)
+	var missing int64
+	for rows.Next() {
+		var expenseType string
+		var total m_types.Money
+		var unconverted int64
+		if err := rows.Scan(&expenseType, &total, &unconverted); err != nil {
+			f.logError(functionName, "Failed to Scan", logger.H{
+				"error":        err,
+				"query_params": queryParams,
+				"currency":     currency,
+			})
+			return nil, m_errors.Map(err)
+		}
+		totals[EnumType(expenseType)] = total
+		missing += unconverted
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, m_errors.Map(err)
+	}
+
+	if missing > 0 {
+		return nil, fmt.Errorf("%w: %d expenses have no rate to %s", m_currency_rate.ErrRateNotFound, missing, currency)
+	}
+
+	return totals, nil
+}
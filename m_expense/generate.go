@@ -0,0 +1,65 @@
+package m_expense
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rsmrtk/db-fd-model/m_types"
+)
+
+// now returns the time from the clock set in m_options.Options.
+func (f *Facade) now() time.Time {
+	if f.clock != nil {
+		return f.clock()
+	}
+	return time.Now()
+}
+
+// generate fills a missing id with a UUIDv7 and a missing created_at with
+// the clock time, both taken from the same instant.
+func (f *Facade) generate(data *Data) {
+	now := f.now()
+	if data.ExpenseID.IsZero() {
+		data.ExpenseID = m_types.NewUUIDv7At(now)
+	}
+	if data.CreatedAt == nil {
+		data.CreatedAt = &now
+	}
+}
+
+// insertValues returns the columns set in data with their values. Nil
+// columns are left out so that their database defaults apply.
+func insertValues(data *Data) ([]string, []interface{}) {
+	columns := make([]string, 0, len(allFieldsList))
+	values := make([]interface{}, 0, len(allFieldsList))
+	add := func(field Field, value interface{}, set bool) {
+		if set {
+			columns = append(columns, field.String())
+			values = append(values, value)
+		}
+	}
+
+	add(ExpenseID, data.ExpenseID, !data.ExpenseID.IsZero())
+	add(ExpenseName, data.ExpenseName, data.ExpenseName != nil)
+	add(ExpenseAmount, data.ExpenseAmount, data.ExpenseAmount != nil)
+	add(ExpenseCurrency, data.ExpenseCurrency, data.ExpenseCurrency != nil)
+	add(ExpenseType, data.ExpenseType, data.ExpenseType != nil)
+	add(ExpenseDate, data.ExpenseDate, data.ExpenseDate != nil)
+	add(CreatedAt, data.CreatedAt, data.CreatedAt != nil)
+	add(DeletedAt, data.DeletedAt, data.DeletedAt != nil)
+	add(Version, data.Version, data.Version != 0)
+	return columns, values
+}
+
+// insertQuery builds an INSERT of the columns set in data.
+func insertQuery(data *Data) (string, []interface{}) {
+	columns, values := insertValues(data)
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		Table, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	return query, values
+}
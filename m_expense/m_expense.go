@@ -2,11 +2,18 @@ package m_expense
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmrtk/db-fd-model/m_audit"
+	"github.com/rsmrtk/db-fd-model/m_errors"
 	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/db-fd-model/m_schema"
+	"github.com/rsmrtk/db-fd-model/m_types"
 	"github.com/rsmrtk/db-fd-model/sql_builder"
 	"github.com/rsmrtk/smartlg/logger"
 )
@@ -20,8 +27,14 @@ const (
 
 type Facade struct {
 	log *logger.Logger
-	db  *sql.DB
+	db  *pgxpool.Pool
+	ex  m_options.Executor
 	//
+	router *m_options.Router
+	clock  func() time.Time
+
+	softDelete     bool
+	includeDeleted bool
 }
 
 func New(o *m_options.Options) *Facade {
@@ -29,6 +42,10 @@ func New(o *m_options.Options) *Facade {
 		log: o.Log,
 		db:  o.DB,
 		//
+		router: o.Router,
+		clock:  o.Clock,
+
+		softDelete: o.SoftDeletes(Table),
 	}
 }
 
@@ -36,13 +53,53 @@ func (f *Facade) logError(functionName string, msg string, h logger.H) {
 	f.log.Error(fmt.Sprintf("[%s.%s - %s] %s", Package, functionName, Table, msg), h)
 }
 
+// WithExecutor returns a copy of the facade bound to ex, e.g. a pgx.Tx
+// shared with other facades.
+func (f *Facade) WithExecutor(ex m_options.Executor) *Facade {
+	bound := *f
+	bound.ex = ex
+	return &bound
+}
+
+// executor returns the bound executor, the executor carried by ctx, or the
+// shared pool.
+func (f *Facade) executor(ctx context.Context) m_options.Executor {
+	if f.ex != nil {
+		return f.ex
+	}
+	if ex, ok := m_options.ExecutorFromContext(ctx); ok {
+		return ex
+	}
+	return f.db
+}
+
+// reader is like executor, but sends reads to a replica when replicas are
+// configured and ctx was not marked with m_options.WithPrimary.
+func (f *Facade) reader(ctx context.Context) m_options.Executor {
+	if f.ex != nil {
+		return f.ex
+	}
+	if ex, ok := m_options.ExecutorFromContext(ctx); ok {
+		return ex
+	}
+	if f.router != nil && !m_options.UsePrimary(ctx) {
+		return f.router.Reader()
+	}
+	return f.db
+}
+
 type Data struct {
-	ExpenseID     interface{}
-	ExpenseName   interface{}
-	ExpenseAmount sql.NullFloat64
-	ExpenseType   interface{}
-	ExpenseDate   sql.NullTime
-	CreatedAt     sql.NullTime
+	ExpenseID       m_types.UUID
+	ExpenseName     *string
+	ExpenseAmount   *m_types.Money
+	ExpenseCurrency *m_types.Currency
+	ExpenseType     *EnumType
+	ExpenseDate     *time.Time
+	CreatedAt       *time.Time
+	DeletedAt       *time.Time
+	Version         int64 // Incremented by every write, see UpdateVersion
+
+	original *Data // As read from the database, see Save
 }
 
 func (data *Data) Map() map[string]any {
@@ -50,31 +107,81 @@ func (data *Data) Map() map[string]any {
 	out[string(ExpenseID)] = data.ExpenseID
 	out[string(ExpenseName)] = data.ExpenseName
 	out[string(ExpenseAmount)] = data.ExpenseAmount
+	out[string(ExpenseCurrency)] = data.ExpenseCurrency
 	out[string(ExpenseType)] = data.ExpenseType
 	out[string(ExpenseDate)] = data.ExpenseDate
 	out[string(CreatedAt)] = data.CreatedAt
+	out[string(DeletedAt)] = data.DeletedAt
+	out[string(Version)] = data.Version
 	return out
 }
 
 type Field string
 
 const (
-	ExpenseID     Field = "expense_id"
-	ExpenseName   Field = "expense_name"
-	ExpenseAmount Field = "expense_amount"
-	ExpenseType   Field = "expense_type"
-	ExpenseDate   Field = "expense_date"
-	CreatedAt     Field = "created_at"
+	ExpenseID       Field = "expense_id"
+	ExpenseName     Field = "expense_name"
+	ExpenseAmount   Field = "expense_amount"
+	ExpenseCurrency Field = "expense_currency"
+	ExpenseType     Field = "expense_type"
+	ExpenseDate     Field = "expense_date"
+	CreatedAt       Field = "created_at"
+	DeletedAt       Field = "deleted_at"
+	Version         Field = "version"
+)
+
+// EnumType mirrors expense_type_enum.
+type EnumType string
+
+const (
+	EnumTypeFood               EnumType = "food"
+	EnumTypeRestaurants        EnumType = "restaurants"
+	EnumTypeEntertainment      EnumType = "entertainment"
+	EnumTypeDwelling           EnumType = "dwelling"
+	EnumTypeUtilities          EnumType = "utilities"
+	EnumTypeHouseholdPurchases EnumType = "household_purchases"
+	EnumTypeTransfer           EnumType = "transfer"
+	EnumTypeOthers             EnumType = "others"
 )
 
+func (e EnumType) String() string {
+	return string(e)
+}
+
+func (e *EnumType) IsValid() bool {
+	switch *e {
+	case EnumTypeFood, EnumTypeRestaurants, EnumTypeEntertainment, EnumTypeDwelling,
+		EnumTypeUtilities, EnumTypeHouseholdPurchases, EnumTypeTransfer, EnumTypeOthers:
+		return true
+	}
+	return false
+}
+
+// GetAllEnumTypes returns every EnumType in expense_type_enum order.
+func GetAllEnumTypes() []EnumType {
+	return []EnumType{
+		EnumTypeFood,
+		EnumTypeRestaurants,
+		EnumTypeEntertainment,
+		EnumTypeDwelling,
+		EnumTypeUtilities,
+		EnumTypeHouseholdPurchases,
+		EnumTypeTransfer,
+		EnumTypeOthers,
+	}
+}
+
 func GetAllFields() []Field {
 	return []Field{
 		ExpenseID,
 		ExpenseName,
 		ExpenseAmount,
+		ExpenseCurrency,
 		ExpenseType,
 		ExpenseDate,
 		CreatedAt,
+		DeletedAt,
+		Version,
 	}
 }
 
@@ -85,12 +192,15 @@ func (f Field) String() string {
 }
 
 var fieldsMap = map[Field]func(data *Data) interface{}{
-	ExpenseID:     func(data *Data) interface{} { return &data.ExpenseID },
-	ExpenseName:   func(data *Data) interface{} { return &data.ExpenseName },
-	ExpenseAmount: func(data *Data) interface{} { return &data.ExpenseAmount },
-	ExpenseType:   func(data *Data) interface{} { return &data.ExpenseType },
-	ExpenseDate:   func(data *Data) interface{} { return &data.ExpenseDate },
-	CreatedAt:     func(data *Data) interface{} { return &data.CreatedAt },
+	ExpenseID:       func(data *Data) interface{} { return &data.ExpenseID },
+	ExpenseName:     func(data *Data) interface{} { return &data.ExpenseName },
+	ExpenseAmount:   func(data *Data) interface{} { return &data.ExpenseAmount },
+	ExpenseCurrency: func(data *Data) interface{} { return &data.ExpenseCurrency },
+	ExpenseType:     func(data *Data) interface{} { return &data.ExpenseType },
+	ExpenseDate:     func(data *Data) interface{} { return &data.ExpenseDate },
+	CreatedAt:       func(data *Data) interface{} { return &data.CreatedAt },
+	DeletedAt:       func(data *Data) interface{} { return &data.DeletedAt },
+	Version:         func(data *Data) interface{} { return &data.Version },
 }
 
 func (data *Data) fieldPtrs(fields []Field) []interface{} {
@@ -103,7 +213,7 @@ func (data *Data) fieldPtrs(fields []Field) []interface{} {
 }
 
 type PrimaryKey struct {
-	ExpenseID string
+	ExpenseID m_types.UUID
 }
 
 func GetColumns() []string {
@@ -111,22 +221,56 @@ func GetColumns() []string {
 		ExpenseID.String(),
 		ExpenseName.String(),
 		ExpenseAmount.String(),
+		ExpenseCurrency.String(),
 		ExpenseType.String(),
 		ExpenseDate.String(),
 		CreatedAt.String(),
+		DeletedAt.String(),
+		Version.String(),
 	}
 }
 
 var allStringFields = GetColumns()
 
+// GetSchema describes how Data maps the expenses table, for drift checks.
+func GetSchema() m_schema.Table {
+	return m_schema.Table{
+		Name: Table,
+		Columns: []m_schema.Column{
+			{Name: ExpenseID.String(), GoType: "m_types.UUID", PgTypes: []string{"uuid"}},
+			{Name: ExpenseName.String(), GoType: "*string", PgTypes: []string{"character varying", "text"}, Nullable: true},
+			{Name: ExpenseAmount.String(), GoType: "*m_types.Money", PgTypes: []string{"numeric"}, Nullable: true},
+			{Name: ExpenseCurrency.String(), GoType: "*m_types.Currency", PgTypes: []string{"character", "character varying", "text"}, Nullable: true},
+			{Name: ExpenseType.String(), GoType: "*EnumType", PgTypes: []string{"USER-DEFINED"}, Nullable: true,
+				Enum: enumTypeStrings()},
+			{Name: ExpenseDate.String(), GoType: "*time.Time", PgTypes: []string{"timestamp with time zone", "timestamp without time zone", "date"}, Nullable: true},
+			{Name: CreatedAt.String(), GoType: "*time.Time", PgTypes: []string{"timestamp with time zone", "timestamp without time zone"}, Nullable: true},
+			{Name: DeletedAt.String(), GoType: "*time.Time", PgTypes: []string{"timestamp with time zone", "timestamp without time zone"}, Nullable: true},
+			{Name: Version.String(), GoType: "int64", PgTypes: []string{"bigint", "integer"}},
+		},
+	}
+}
+
+func enumTypeStrings() []string {
+	values := GetAllEnumTypes()
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = v.String()
+	}
+	return out
+}
+
 func GetValues(data *Data) []interface{} {
 	return []interface{}{
 		data.ExpenseID,
 		data.ExpenseName,
 		data.ExpenseAmount,
+		data.ExpenseCurrency,
 		data.ExpenseType,
 		data.ExpenseDate,
 		data.CreatedAt,
+		data.DeletedAt,
+		data.Version,
 	}
 }
 
@@ -185,9 +329,14 @@ func SelectQuery(fields []Field) string {
 }
 
 func ConstructWhereClause(queryParams []QueryParam) (whereClause string, args []interface{}) {
+	return constructWhereClause(queryParams, 1)
+}
+
+// constructWhereClause numbers placeholders from paramCounter so the clause
+// can follow other parameters, e.g. the SET list of an UPDATE.
+func constructWhereClause(queryParams []QueryParam, paramCounter int) (whereClause string, args []interface{}) {
 	whereClauses := make([]string, len(queryParams))
 	args = make([]interface{}, 0, len(queryParams))
-	paramCounter := 1
 
 	for i, qp := range queryParams {
 		if (qp.Operator == OpIs || qp.Operator == OpIsNot) && qp.Value == nil {
@@ -196,76 +345,65 @@ func ConstructWhereClause(queryParams []QueryParam) (whereClause string, args []
 		}
 
 		if qp.Operator == OpIn {
-			// Handle IN operator for PostgreSQL
-			values := qp.Value.([]interface{})
-			placeholders := make([]string, len(values))
-			for j := range values {
-				placeholders[j] = fmt.Sprintf("$%d", paramCounter)
-				paramCounter++
-				args = append(args, values[j])
-			}
-			whereClauses[i] = fmt.Sprintf(`"%s" IN (%s)`, qp.Field, strings.Join(placeholders, ", "))
+			// PostgreSQL binds the whole slice as one array parameter
+			whereClauses[i] = fmt.Sprintf(`"%s" = ANY($%d)`, qp.Field, paramCounter)
 		} else {
 			whereClauses[i] = fmt.Sprintf(`"%s" %s $%d`, qp.Field, qp.Operator, paramCounter)
-			paramCounter++
-			args = append(args, qp.Value)
 		}
+		paramCounter++
+		args = append(args, qp.Value)
 	}
 
 	return strings.Join(whereClauses, " AND "), args
 }
 
+// CreateOrUpdate inserts data or overwrites the expense with the same id,
+// keeping its created_at and deleted_at. Upsert takes other conflict
+// targets and policies.
 func (f *Facade) CreateOrUpdate(
 	ctx context.Context,
 	data *Data,
 ) error {
-	query := fmt.Sprintf(`
-		INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (expense_id) DO UPDATE SET
-			expense_name = EXCLUDED.expense_name,
-			expense_amount = EXCLUDED.expense_amount,
-			expense_type = EXCLUDED.expense_type,
-			expense_date = EXCLUDED.expense_date,
-			created_at = EXCLUDED.created_at`,
-		Table, strings.Join(allStringFields, ", "))
-
-	_, err := f.db.ExecContext(ctx, query, GetValues(data)...)
+	return f.CreateOrUpdateTx(ctx, f.executor(ctx), data)
+}
+
+func (f *Facade) CreateOrUpdateTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	data *Data,
+) error {
+	if err := validateData(data); err != nil {
+		return err
+	}
+	f.generate(data)
+	defaultCurrency(data)
+	defaultVersion(data)
+
+	query, _, _ := upsertQuery(Upsert{}) // The zero Upsert always renders
+	_, err := f.exec(ctx, tx, query, GetValues(data)...)
 	if err != nil {
 		f.logError("CreateOrUpdate", "Failed to execute", logger.H{
 			"error": err,
 			"data":  data,
 		})
-		return err
+		return m_errors.Map(err)
 	}
 
 	return nil
 }
 
+// Create inserts data. A missing id is generated as a UUIDv7 and a missing
+// created_at is taken from the clock; columns left nil get their database
+// defaults. data is then filled with the row as stored.
 func (f *Facade) Create(ctx context.Context, data *Data) error {
-	query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6)`,
-		Table, strings.Join(allStringFields, ", "))
-
-	_, err := f.db.ExecContext(ctx, query, GetValues(data)...)
-	if err != nil {
-		f.logError("Create", "Failed to execute", logger.H{
-			"error": err,
-			"data":  data,
-		})
-		return err
-	}
-
-	return nil
+	return f.CreateTx(ctx, f.executor(ctx), data)
 }
 
 func (f *Facade) Exists(
 	ctx context.Context,
 	pk PrimaryKey,
-) bool {
-	query := fmt.Sprintf(`SELECT 1 FROM %s WHERE expense_id = $1 LIMIT 1`, Table)
-
-	var exists int
-	err := f.db.QueryRowContext(ctx, query, pk.ExpenseID).Scan(&exists)
-	return err == nil
+) (bool, error) {
+	return f.ExistTx(ctx, f.reader(ctx), pk)
 }
 
 func (f *Facade) Get(
@@ -273,6 +411,37 @@ func (f *Facade) Get(
 	queryParams []QueryParam,
 	fields []Field,
 ) ([]*Data, error) {
+	return f.get(ctx, "Get", f.reader(ctx), queryParams, fields)
+}
+
+func (f *Facade) get(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	queryParams []QueryParam,
+	fields []Field,
+) ([]*Data, error) {
+	res := make([]*Data, 0)
+	err := f.getIter(ctx, functionName, ex, queryParams, fields, func(data *Data) {
+		res = append(res, data)
+	})
+	if err != nil {
+		return nil, m_errors.Map(err)
+	}
+
+	return res, nil
+}
+
+func (f *Facade) getIter(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	queryParams []QueryParam,
+	fields []Field,
+	callback func(*Data),
+) error {
+	queryParams = f.scoped(queryParams)
+
 	// Construct SQL query
 	queryString := SelectQuery(fields)
 	whereClauses, args := ConstructWhereClause(queryParams)
@@ -280,32 +449,35 @@ func (f *Facade) Get(
 		queryString += " WHERE " + whereClauses
 	}
 
-	rows, err := f.db.QueryContext(ctx, queryString, args...)
+	if len(fields) == 0 {
+		fields = allFieldsList
+	}
+
+	rows, err := ex.Query(ctx, queryString, args...)
 	if err != nil {
-		f.logError("Get", "Failed to query", logger.H{
+		f.logError(functionName, "Failed to query", logger.H{
 			"error":        err,
 			"query_params": queryParams,
 			"fields":       fields,
 		})
-		return nil, err
+		return m_errors.Map(err)
 	}
 	defer rows.Close()
 
-	res := make([]*Data, 0)
 	for rows.Next() {
 		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("Get", "Failed to Scan", logger.H{
+		if err := data.scan(rows, fields); err != nil {
+			f.logError(functionName, "Failed to Scan", logger.H{
 				"error":        err,
 				"query_params": queryParams,
 				"fields":       fields,
 			})
-			return nil, err
+			return m_errors.Map(err)
 		}
-		res = append(res, &data)
+		callback(&data)
 	}
 
-	return res, nil
+	return m_errors.Map(rows.Err())
 }
 
 func (f *Facade) Find(
@@ -313,22 +485,36 @@ func (f *Facade) Find(
 	pk PrimaryKey,
 	fields []Field,
 ) (*Data, error) {
+	return f.find(ctx, "Find", f.reader(ctx), pk, fields)
+}
+
+func (f *Facade) find(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	pk PrimaryKey,
+	fields []Field,
+) (*Data, error) {
+	if len(fields) == 0 {
+		fields = allFieldsList
+	}
+
 	queryString := SelectQuery(fields)
-	queryString += " WHERE expense_id = $1 LIMIT 1"
+	queryString += f.andScope(" WHERE expense_id = $1") + " LIMIT 1"
 
-	row := f.db.QueryRowContext(ctx, queryString, pk.ExpenseID)
+	row := ex.QueryRow(ctx, queryString, pk.ExpenseID)
 
 	var data Data
-	err := row.Scan(data.fieldPtrs(fields)...)
+	err := data.scan(row, fields)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, m_errors.Map(err)
 		}
-		f.logError("Find", "Failed to Scan", logger.H{
+		f.logError(functionName, "Failed to Scan", logger.H{
 			"error":  err,
 			"fields": fields,
 		})
-		return nil, err
+		return nil, m_errors.Map(err)
 	}
 
 	return &data, nil
@@ -343,266 +529,190 @@ func (f *Facade) Retrieve(
 
 func (f *Facade) CreateTx(
 	ctx context.Context,
-	tx *sql.Tx,
+	tx m_options.Executor,
 	data *Data,
 ) error {
-	query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6)`,
-		Table, strings.Join(allStringFields, ", "))
+	if err := validateData(data); err != nil {
+		return err
+	}
+	f.generate(data)
+
+	query, values := insertQuery(data)
+	query += returning(allFieldsList)
 
-	_, err := tx.ExecContext(ctx, query, GetValues(data)...)
+	// Read back the stored row, including database defaults
+	err := m_audit.Run(ctx, tx, func(ctx context.Context, tx m_options.Executor) error {
+		return data.scan(tx.QueryRow(ctx, query, values...), allFieldsList)
+	})
 	if err != nil {
-		f.logError("CreateTx", "Failed to execute", logger.H{
-			"error": err,
-			"data":  data,
+		f.logError("CreateTx", "Failed to QueryRow", logger.H{
+			"error": err, "data": data,
 		})
-		return err
+		return m_errors.Map(err)
 	}
 	return nil
 }
 
+// UpdateTx sets data on the expense with pk and returns the number of rows
+// affected, 0 when no expense matched. With an expected version it fails like
+// UpdateVersionTx instead.
 func (f *Facade) UpdateTx(
 	ctx context.Context,
-	tx *sql.Tx,
+	tx m_options.Executor,
 	pk PrimaryKey,
-	data UpdateFields,
-) error {
-	setClauses := make([]string, 0, len(data))
-	args := make([]interface{}, 0, len(data)+1)
-	paramCounter := 1
-
-	for field, value := range data {
-		setClauses = append(setClauses, fmt.Sprintf(`"%s" = $%d`, field.String(), paramCounter))
-		args = append(args, value)
-		paramCounter++
+	update Updater,
+) (int64, error) {
+	data := fieldsOf(update)
+	if len(data) == 0 {
+		return 0, nil
+	}
+	if version := expectedVersion(data); version != 0 {
+		if err := f.UpdateVersionTx(ctx, tx, pk, version, data); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+	if err := validateUpdate(data); err != nil {
+		return 0, err
 	}
-	args = append(args, pk.ExpenseID)
-
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE expense_id = $%d",
-		Table, strings.Join(setClauses, ", "), paramCounter)
 
-	_, err := tx.ExecContext(ctx, query, args...)
+	query, args := updateQuery(pk, data)
+	tag, err := f.exec(ctx, tx, f.andScope(query), args...)
 	if err != nil {
 		f.logError("UpdateTx", "Failed to execute", logger.H{
 			"error":      err,
 			"primaryKey": pk,
 			"data":       data,
 		})
-		return err
+		return 0, m_errors.Map(err)
 	}
-	return nil
+	return tag.RowsAffected(), nil
+}
+
+// updateQuery builds the UPDATE of the expense with pk.
+func updateQuery(pk PrimaryKey, data UpdateFields) (string, []interface{}) {
+	setClauses := make([]string, 0, len(data))
+	args := make([]interface{}, 0, len(data)+1)
+	paramCounter := 1
+
+	for _, field := range data.sortedFields() {
+		value := data[field]
+		if field == Version {
+			continue
+		}
+		var clause string
+		clause, args = sql_builder.SetClause(fmt.Sprintf(`"%s"`, field), value, args)
+		setClauses = append(setClauses, clause)
+		paramCounter = len(args) + 1
+	}
+	setClauses = append(setClauses, `"version" = "version" + 1`)
+	args = append(args, pk.ExpenseID)
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE expense_id = $%d",
+		Table, strings.Join(setClauses, ", "), paramCounter)
+	return query, args
 }
 
 func (f *Facade) FindTx(
 	ctx context.Context,
-	tx *sql.Tx,
+	tx m_options.Executor,
 	pk PrimaryKey,
 	fields []Field,
 ) (*Data, error) {
-	queryString := SelectQuery(fields)
-	queryString += " WHERE expense_id = $1 LIMIT 1"
-
-	row := tx.QueryRowContext(ctx, queryString, pk.ExpenseID)
-
-	var data Data
-	err := row.Scan(data.fieldPtrs(fields)...)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, err
-		}
-		f.logError("FindTx", "Failed to Scan", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return nil, err
-	}
-
-	return &data, nil
+	return f.find(ctx, "FindTx", tx, pk, fields)
 }
 
 func (f *Facade) GetByBuilder(ctx context.Context, builder *sql_builder.Builder[Field]) ([]*Data, error) {
-	if builder == nil {
-		return nil, fmt.Errorf("builder cannot be nil")
-	}
-	queryStr := builder.StringPostgres()
-	queryArgs := builder.ArgsPostgres()
-	fields := builder.Fields()
-
-	rows, err := f.db.QueryContext(ctx, queryStr, queryArgs...)
-	if err != nil {
-		f.logError("GetByBuilder", "Failed to query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return nil, err
-	}
-	defer rows.Close()
-
-	res := make([]*Data, 0)
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByBuilder", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return nil, err
-		}
-		res = append(res, &data)
-	}
-
-	return res, nil
+	return f.getByBuilder(ctx, "GetByBuilder", f.reader(ctx), builder)
 }
 
-func (f *Facade) GetByBuilderTx(ctx context.Context, tx *sql.Tx, builder *sql_builder.Builder[Field]) ([]*Data, error) {
-	if builder == nil {
-		return nil, fmt.Errorf("builder cannot be nil")
-	}
-	queryStr := builder.StringPostgres()
-	queryArgs := builder.ArgsPostgres()
-	fields := builder.Fields()
-
-	rows, err := tx.QueryContext(ctx, queryStr, queryArgs...)
-	if err != nil {
-		f.logError("GetByBuilderTx", "Failed to query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return nil, err
-	}
-	defer rows.Close()
+func (f *Facade) GetByBuilderTx(ctx context.Context, tx m_options.Executor, builder *sql_builder.Builder[Field]) ([]*Data, error) {
+	return f.getByBuilder(ctx, "GetByBuilderTx", tx, builder)
+}
 
+func (f *Facade) getByBuilder(ctx context.Context, functionName string, ex m_options.Executor, builder *sql_builder.Builder[Field]) ([]*Data, error) {
 	res := make([]*Data, 0)
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByBuilderTx", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return nil, err
-		}
-		res = append(res, &data)
+	err := f.getByBuilderIter(ctx, functionName, ex, builder, func(data *Data) {
+		res = append(res, data)
+	})
+	if err != nil {
+		return nil, m_errors.Map(err)
 	}
 
 	return res, nil
 }
 
 func (f *Facade) GetByBuilderIter(ctx context.Context, builder *sql_builder.Builder[Field], callback func(*Data)) error {
-	if builder == nil {
-		return fmt.Errorf("builder cannot be nil")
-	}
-	queryStr := builder.StringPostgres()
-	queryArgs := builder.ArgsPostgres()
-	fields := builder.Fields()
-
-	rows, err := f.db.QueryContext(ctx, queryStr, queryArgs...)
-	if err != nil {
-		f.logError("GetByBuilderIter", "Failed to query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByBuilderIter", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return err
-		}
-		callback(&data)
-	}
+	return f.getByBuilderIter(ctx, "GetByBuilderIter", f.reader(ctx), builder, callback)
+}
 
-	return nil
+func (f *Facade) GetByBuilderTxIter(ctx context.Context, tx m_options.Executor, builder *sql_builder.Builder[Field], callback func(*Data)) error {
+	return f.getByBuilderIter(ctx, "GetByBuilderTxIter", tx, builder, callback)
 }
 
-func (f *Facade) GetByBuilderTxIter(ctx context.Context, tx *sql.Tx, builder *sql_builder.Builder[Field], callback func(*Data)) error {
+func (f *Facade) getByBuilderIter(ctx context.Context, functionName string, ex m_options.Executor, builder *sql_builder.Builder[Field], callback func(*Data)) error {
 	if builder == nil {
 		return fmt.Errorf("builder cannot be nil")
 	}
-	queryStr := builder.StringPostgres()
+	queryStr := builder.StringPostgresScoped(f.scope()...)
 	queryArgs := builder.ArgsPostgres()
 	fields := builder.Fields()
+	if len(fields) == 0 {
+		fields = allFieldsList
+	}
 
-	rows, err := tx.QueryContext(ctx, queryStr, queryArgs...)
+	rows, err := ex.Query(ctx, queryStr, queryArgs...)
 	if err != nil {
-		f.logError("GetByBuilderTxIter", "Failed to query", logger.H{
+		f.logError(functionName, "Failed to query", logger.H{
 			"error":  err,
 			"fields": fields,
 		})
-		return err
+		return m_errors.Map(err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByBuilderTxIter", "Failed to Scan", logger.H{
+		if err := data.scan(rows, fields); err != nil {
+			f.logError(functionName, "Failed to Scan", logger.H{
 				"error":  err,
 				"fields": fields,
 			})
-			return err
+			return m_errors.Map(err)
 		}
 		callback(&data)
 	}
 
-	return nil
+	return m_errors.Map(rows.Err())
 }
 
 func (f *Facade) GetTx(
 	ctx context.Context,
-	tx *sql.Tx,
+	tx m_options.Executor,
 	queryParams []QueryParam,
 	fields []Field,
 ) ([]*Data, error) {
-	q := SelectQuery(fields)
-	whereClause, args := ConstructWhereClause(queryParams)
-	if len(queryParams) > 0 {
-		q += " WHERE " + whereClause
-	}
-
-	rows, err := tx.QueryContext(ctx, q, args...)
-	if err != nil {
-		f.logError("GetTx", "Failed to query", logger.H{
-			"error":       err,
-			"queryParams": queryParams,
-			"fields":      fields,
-		})
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []*Data
-	for rows.Next() {
-		var d Data
-		if err := rows.Scan(d.fieldPtrs(fields)...); err != nil {
-			f.logError("GetTx", "Failed to Scan", logger.H{
-				"error":       err,
-				"queryParams": queryParams,
-				"fields":      fields,
-			})
-			return nil, err
-		}
-		res = append(res, &d)
-	}
-
-	return res, nil
+	return f.get(ctx, "GetTx", tx, queryParams, fields)
 }
 
 func (f *Facade) ExistTx(
 	ctx context.Context,
-	tx *sql.Tx,
+	tx m_options.Executor,
 	pk PrimaryKey,
-) bool {
-	query := fmt.Sprintf(`SELECT 1 FROM %s WHERE expense_id = $1 LIMIT 1`, Table)
+) (bool, error) {
+	query := fmt.Sprintf(`SELECT EXISTS (%s)`,
+		f.andScope(fmt.Sprintf(`SELECT 1 FROM %s WHERE expense_id = $1`, Table)))
 
-	var exists int
-	err := tx.QueryRowContext(ctx, query, pk.ExpenseID).Scan(&exists)
-	return err == nil
+	var exists bool
+	err := tx.QueryRow(ctx, query, pk.ExpenseID).Scan(&exists)
+	if err != nil {
+		f.logError("ExistTx", "Failed to QueryRow", logger.H{
+			"error":      err,
+			"primaryKey": pk,
+		})
+		return false, m_errors.Map(err)
+	}
+	return exists, nil
 }
 
 func (c *Facade) InitBuilder() *sql_builder.Builder[Field] {
@@ -611,100 +721,117 @@ func (c *Facade) InitBuilder() *sql_builder.Builder[Field] {
 	return b
 }
 
+// Update sets data on the expense with pk and returns the number of rows
+// affected, 0 when no expense matched.
 func (f *Facade) Update(
 	ctx context.Context,
 	pk PrimaryKey,
-	data UpdateFields,
-) error {
-	setClauses := make([]string, 0, len(data))
-	args := make([]interface{}, 0, len(data)+1)
-	paramCounter := 1
-
-	for field, value := range data {
-		setClauses = append(setClauses, fmt.Sprintf(`"%s" = $%d`, field.String(), paramCounter))
-		args = append(args, value)
-		paramCounter++
-	}
-	args = append(args, pk.ExpenseID)
-
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE expense_id = $%d",
-		Table, strings.Join(setClauses, ", "), paramCounter)
-
-	_, err := f.db.ExecContext(ctx, query, args...)
-	if err != nil {
-		f.logError("Update", "Failed to execute", logger.H{
-			"error": err,
-			"data":  data,
-		})
-		return fmt.Errorf("failed to update file record: %w", err)
-	}
-
-	return nil
+	update Updater,
+) (int64, error) {
+	return f.UpdateTx(ctx, f.executor(ctx), pk, update)
 }
 
+// UpdateByParams sets data on every expense matching queryParams and returns
+// the number of rows affected.
 func (f *Facade) UpdateByParams(
 	ctx context.Context,
 	queryParams []QueryParam,
-	data UpdateFields,
-) error {
-	// Construct SQL query
-	whereClauses, whereArgs := ConstructWhereClause(queryParams)
-	setClauses := make([]string, 0, len(data))
-	args := make([]interface{}, 0, len(data)+len(whereArgs))
-	paramCounter := 1
-
-	// Construct SET clause
-	for field, value := range data {
-		setClauses = append(setClauses, fmt.Sprintf(`"%s" = $%d`, field.String(), paramCounter))
-		args = append(args, value)
-		paramCounter++
-	}
+	update Updater,
+) (int64, error) {
+	return f.UpdateByParamsTx(ctx, f.executor(ctx), queryParams, update)
+}
 
-	// Add WHERE args
-	for _, arg := range whereArgs {
-		args = append(args, arg)
+func (f *Facade) UpdateByParamsTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	queryParams []QueryParam,
+	update Updater,
+) (int64, error) {
+	data := fieldsOf(update)
+	if len(data) == 0 {
+		return 0, nil
 	}
-
-	// Update WHERE clause placeholders
-	updatedWhereClauses := whereClauses
-	for i := 1; i <= len(whereArgs); i++ {
-		old := fmt.Sprintf("$%d", i)
-		new := fmt.Sprintf("$%d", paramCounter)
-		updatedWhereClauses = strings.Replace(updatedWhereClauses, old, new, 1)
-		paramCounter++
+	if err := validateUpdate(data); err != nil {
+		return 0, err
 	}
 
-	queryString := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
-		Table, strings.Join(setClauses, ", "), updatedWhereClauses)
-
-	_, err := f.db.ExecContext(ctx, queryString, args...)
+	queryString, args := updateByParamsQuery(f.scoped(queryParams), data)
+	tag, err := f.exec(ctx, tx, queryString, args...)
 	if err != nil {
 		f.logError("UpdateByParams", "Failed to execute", logger.H{
 			"error":        err,
 			"query_params": queryParams,
 			"data":         data,
 		})
-		return fmt.Errorf("failed to update file record: %w", err)
+		return 0, m_errors.Map(err)
 	}
 
-	return nil
+	return tag.RowsAffected(), nil
+}
+
+// updateByParamsQuery builds the UPDATE of every expense matching queryParams.
+func updateByParamsQuery(queryParams []QueryParam, data UpdateFields) (string, []interface{}) {
+	if version := expectedVersion(data); version != 0 {
+		queryParams = append(queryParams[:len(queryParams):len(queryParams)], QueryParam{
+			Field: Version, Operator: OpEq, Value: version,
+		})
+	}
+
+	setClauses := make([]string, 0, len(data))
+	args := make([]interface{}, 0, len(data)+len(queryParams))
+	paramCounter := 1
+
+	// Construct SET clause
+	for _, field := range data.sortedFields() {
+		value := data[field]
+		if field == Version {
+			continue
+		}
+		var clause string
+		clause, args = sql_builder.SetClause(fmt.Sprintf(`"%s"`, field), value, args)
+		setClauses = append(setClauses, clause)
+		paramCounter = len(args) + 1
+	}
+	setClauses = append(setClauses, `"version" = "version" + 1`)
+
+	// Construct WHERE clause numbered after the SET placeholders
+	whereClauses, whereArgs := constructWhereClause(queryParams, paramCounter)
+	args = append(args, whereArgs...)
+
+	queryString := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
+		Table, strings.Join(setClauses, ", "), whereClauses)
+	return queryString, args
 }
 
+// Delete removes the expense with pk and returns the number of rows
+// affected, 0 when no expense matched.
 func (f *Facade) Delete(
 	ctx context.Context,
 	pk PrimaryKey,
-) error {
+) (int64, error) {
+	return f.DeleteTx(ctx, f.executor(ctx), pk)
+}
+
+func (f *Facade) DeleteTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	pk PrimaryKey,
+) (int64, error) {
 	query := fmt.Sprintf("DELETE FROM %s WHERE expense_id = $1", Table)
+	args := []interface{}{pk.ExpenseID}
+	if f.softDelete {
+		query, args = f.softDeleteQuery(pk)
+	}
 
-	_, err := f.db.ExecContext(ctx, query, pk.ExpenseID)
+	tag, err := f.exec(ctx, tx, query, args...)
 	if err != nil {
 		f.logError("Delete", "Failed to execute", logger.H{
 			"error": err,
 		})
-		return fmt.Errorf("failed to delete file record: %w", err)
+		return 0, m_errors.Map(err)
 	}
 
-	return nil
+	return tag.RowsAffected(), nil
 }
 
 func (f *Facade) GetIter(
@@ -713,47 +840,49 @@ func (f *Facade) GetIter(
 	fields []Field,
 	callback func(*Data),
 ) error {
-	// Construct SQL query
-	queryString := SelectQuery(fields)
-	whereClauses, args := ConstructWhereClause(queryParams)
-	if len(queryParams) > 0 {
-		queryString += " WHERE " + whereClauses
-	}
+	return f.getIter(ctx, "GetIter", f.reader(ctx), queryParams, fields, callback)
+}
 
-	rows, err := f.db.QueryContext(ctx, queryString, args...)
-	if err != nil {
-		f.logError("GetIter", "Failed to query", logger.H{
-			"error":        err,
-			"query_params": queryParams,
-			"fields":       fields,
-		})
-		return err
-	}
-	defer rows.Close()
+func (f *Facade) GetByPrimaryKeys(
+	ctx context.Context,
+	primaryKeys []PrimaryKey,
+	fields []Field,
+) ([]*Data, error) {
+	return f.getByPrimaryKeys(ctx, "GetByPrimaryKeys", f.reader(ctx), primaryKeys, fields)
+}
 
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetIter", "Failed to Scan", logger.H{
-				"error":        err,
-				"query_params": queryParams,
-				"fields":       fields,
-			})
-			return err
-		}
-		callback(&data)
+func (f *Facade) getByPrimaryKeys(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	primaryKeys []PrimaryKey,
+	fields []Field,
+) ([]*Data, error) {
+	res := []*Data{}
+	err := f.getByPrimaryKeysIter(ctx, functionName, ex, primaryKeys, fields, func(data *Data) {
+		res = append(res, data)
+	})
+	if err != nil {
+		return nil, m_errors.Map(err)
 	}
 
-	return nil
+	return res, nil
 }
 
-func (f *Facade) GetByPrimaryKeys(
+func (f *Facade) getByPrimaryKeysIter(
 	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
 	primaryKeys []PrimaryKey,
 	fields []Field,
-) ([]*Data, error) {
+	callback func(*Data),
+) error {
 	if len(primaryKeys) == 0 {
-		return []*Data{}, nil
+		return nil
+	}
+
+	if len(fields) == 0 {
+		fields = allFieldsList
 	}
 
 	// Create placeholders for IN clause
@@ -765,32 +894,31 @@ func (f *Facade) GetByPrimaryKeys(
 	}
 
 	queryString := SelectQuery(fields)
-	queryString += fmt.Sprintf(" WHERE expense_id IN (%s)", strings.Join(placeholders, ", "))
+	queryString += f.andScope(fmt.Sprintf(" WHERE expense_id IN (%s)", strings.Join(placeholders, ", ")))
 
-	rows, err := f.db.QueryContext(ctx, queryString, args...)
+	rows, err := ex.Query(ctx, queryString, args...)
 	if err != nil {
-		f.logError("GetByPrimaryKeys", "Failed to query", logger.H{
+		f.logError(functionName, "Failed to query", logger.H{
 			"error":  err,
 			"fields": fields,
 		})
-		return nil, err
+		return m_errors.Map(err)
 	}
 	defer rows.Close()
 
-	var res []*Data
 	for rows.Next() {
 		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByPrimaryKeys", "Failed to Scan", logger.H{
+		if err := data.scan(rows, fields); err != nil {
+			f.logError(functionName, "Failed to Scan", logger.H{
 				"error":  err,
 				"fields": fields,
 			})
-			return nil, err
+			return m_errors.Map(err)
 		}
-		res = append(res, &data)
+		callback(&data)
 	}
 
-	return res, nil
+	return m_errors.Map(rows.Err())
 }
 
 func (f *Facade) ListByPrimaryKeys(
@@ -802,54 +930,16 @@ func (f *Facade) ListByPrimaryKeys(
 
 func (f *Facade) GetByPrimaryKeysTx(
 	ctx context.Context,
-	tx *sql.Tx,
+	tx m_options.Executor,
 	primaryKeys []PrimaryKey,
 	fields []Field,
 ) ([]*Data, error) {
-	if len(primaryKeys) == 0 {
-		return []*Data{}, nil
-	}
-
-	// Create placeholders for IN clause
-	placeholders := make([]string, len(primaryKeys))
-	args := make([]interface{}, len(primaryKeys))
-	for i, pk := range primaryKeys {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
-		args[i] = pk.ExpenseID
-	}
-
-	queryString := SelectQuery(fields)
-	queryString += fmt.Sprintf(" WHERE expense_id IN (%s)", strings.Join(placeholders, ", "))
-
-	rows, err := tx.QueryContext(ctx, queryString, args...)
-	if err != nil {
-		f.logError("GetByPrimaryKeysTx", "Failed to query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []*Data
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByPrimaryKeysTx", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return nil, err
-		}
-		res = append(res, &data)
-	}
-
-	return res, nil
+	return f.getByPrimaryKeys(ctx, "GetByPrimaryKeysTx", tx, primaryKeys, fields)
 }
 
 func (f *Facade) ListByPrimaryKeysTx(
 	ctx context.Context,
-	tx *sql.Tx,
+	tx m_options.Executor,
 	primaryKeys []PrimaryKey,
 ) ([]*Data, error) {
 	return f.GetByPrimaryKeysTx(ctx, tx, primaryKeys, allFieldsList)
@@ -861,44 +951,7 @@ func (f *Facade) GetByPrimaryKeysIter(
 	fields []Field,
 	callback func(*Data),
 ) error {
-	if len(primaryKeys) == 0 {
-		return nil
-	}
-
-	// Create placeholders for IN clause
-	placeholders := make([]string, len(primaryKeys))
-	args := make([]interface{}, len(primaryKeys))
-	for i, pk := range primaryKeys {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
-		args[i] = pk.ExpenseID
-	}
-
-	queryString := SelectQuery(fields)
-	queryString += fmt.Sprintf(" WHERE expense_id IN (%s)", strings.Join(placeholders, ", "))
-
-	rows, err := f.db.QueryContext(ctx, queryString, args...)
-	if err != nil {
-		f.logError("GetByPrimaryKeysIter", "Failed to query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByPrimaryKeysIter", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return err
-		}
-		callback(&data)
-	}
-
-	return nil
+	return f.getByPrimaryKeysIter(ctx, "GetByPrimaryKeysIter", f.reader(ctx), primaryKeys, fields, callback)
 }
 
 func (f *Facade) ListByPrimaryKeysIter(
@@ -930,27 +983,33 @@ type OperationRead struct {
 	strFields []string
 	stmt      string
 	args      []interface{}
-	tx        *sql.Tx
+	ex        m_options.Executor
 	qp        []QueryParam
 	pk        *PrimaryKey
 	pks       []PrimaryKey
 	qb        *sql_builder.Builder[Field]
+	primary   bool
+}
+
+// executor returns the executor set with Tx, falling back to the
+// context executor, and then to a replica or the primary pool.
+func (op *OperationRead) executor(ctx context.Context) m_options.Executor {
+	if op.ex != nil {
+		return op.ex
+	}
+	if op.primary {
+		return op.f.executor(ctx)
+	}
+	return op.f.reader(ctx)
 }
 
 func (op *OperationRead) Exists(
 	ctx context.Context,
-) bool {
-	if op.pk != nil {
-		query := fmt.Sprintf(`SELECT 1 FROM %s WHERE expense_id = $1 LIMIT 1`, Table)
-		var exists int
-		if op.tx != nil {
-			err := op.tx.QueryRowContext(ctx, query, op.pk.ExpenseID).Scan(&exists)
-			return err == nil
-		}
-		err := op.f.db.QueryRowContext(ctx, query, op.pk.ExpenseID).Scan(&exists)
-		return err == nil
+) (bool, error) {
+	if op.pk == nil {
+		return false, fmt.Errorf("primary key not set")
 	}
-	return false
+	return op.f.ExistTx(ctx, op.executor(ctx), *op.pk)
 }
 
 func (op *OperationRead) Columns(fields ...Field) *OperationRead {
@@ -966,8 +1025,26 @@ func (op *OperationRead) Params(queryParams []QueryParam) *OperationRead {
 	return op
 }
 
-func (op *OperationRead) Tx(tx *sql.Tx) *OperationRead {
-	op.tx = tx
+func (op *OperationRead) Tx(tx m_options.Executor) *OperationRead {
+	op.ex = tx
+	return op
+}
+
+func (op *OperationRead) ByKey(pk PrimaryKey) *OperationRead {
+	op.pk = &pk
+	return op
+}
+
+// Primary reads from the primary instead of a replica, e.g. to read your
+// own writes.
+func (op *OperationRead) Primary() *OperationRead {
+	op.primary = true
+	return op
+}
+
+// IncludeDeleted also returns soft-deleted expenses.
+func (op *OperationRead) IncludeDeleted() *OperationRead {
+	op.f = op.f.IncludeDeleted()
 	return op
 }
 
@@ -1025,25 +1102,19 @@ func (op *OperationRead) GetCount(ctx context.Context) (int64, error) {
 	var queryStr string
 	var queryArgs []interface{}
 	if op.qb != nil {
-		queryStr = op.qb.StringPostgres()
+		queryStr = op.qb.StringPostgresScoped(op.f.scope()...)
 		queryArgs = op.qb.ArgsPostgres()
 	}
 
 	var count int64
-	var err error
-	if op.tx != nil {
-		err = op.tx.QueryRowContext(ctx, queryStr, queryArgs...).Scan(&count)
-	} else {
-		err = op.f.db.QueryRowContext(ctx, queryStr, queryArgs...).Scan(&count)
-	}
-
+	err := op.executor(ctx).QueryRow(ctx, queryStr, queryArgs...).Scan(&count)
 	if err != nil {
 		op.f.logError("GetCount", "Failed to Scan", logger.H{
 			"error": err,
 			"query": queryStr,
 			"args":  queryArgs,
 		})
-		return 0, err
+		return 0, m_errors.Map(err)
 	}
 
 	return count, nil
@@ -1052,48 +1123,43 @@ func (op *OperationRead) GetCount(ctx context.Context) (int64, error) {
 func (op *OperationRead) Rows(ctx context.Context) ([]*Data, error) {
 	op.stringColumns()
 
-	var rows *sql.Rows
+	var rows pgx.Rows
 	var err error
+	ex := op.executor(ctx)
 
 	if op.qb != nil {
-		queryStr := op.qb.StringPostgres()
+		queryStr := op.qb.StringPostgresScoped(op.f.scope()...)
 		queryArgs := op.qb.ArgsPostgres()
-		if op.tx != nil {
-			rows, err = op.tx.QueryContext(ctx, queryStr, queryArgs...)
-		} else {
-			rows, err = op.f.db.QueryContext(ctx, queryStr, queryArgs...)
-		}
+		rows, err = ex.Query(ctx, queryStr, queryArgs...)
 	} else if op.qp != nil {
+		qp := op.f.scoped(op.qp)
 		queryString := SelectQuery(op.fields)
-		whereClauses, args := ConstructWhereClause(op.qp)
-		if len(op.qp) > 0 {
+		whereClauses, args := ConstructWhereClause(qp)
+		if len(qp) > 0 {
 			queryString += " WHERE " + whereClauses
 		}
-		if op.tx != nil {
-			rows, err = op.tx.QueryContext(ctx, queryString, args...)
-		} else {
-			rows, err = op.f.db.QueryContext(ctx, queryString, args...)
-		}
+		rows, err = ex.Query(ctx, queryString, args...)
 	} else if op.pks != nil {
-		data, err := op.f.GetByPrimaryKeys(ctx, op.pks, op.fields)
-		return data, err
+		return op.f.getByPrimaryKeys(ctx, "OperationRead Rows", ex, op.pks, op.fields)
+	} else {
+		return nil, fmt.Errorf("nothing to read: set Params, ByKeys or a builder")
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, m_errors.Map(err)
 	}
 	defer rows.Close()
 
 	res := make([]*Data, 0)
 	for rows.Next() {
 		var data Data
-		if err := rows.Scan(data.fieldPtrs(op.fields)...); err != nil {
-			return nil, err
+		if err := data.scan(rows, op.fields); err != nil {
+			return nil, m_errors.Map(err)
 		}
 		res = append(res, &data)
 	}
 
-	return res, nil
+	return res, m_errors.Map(rows.Err())
 }
 
 func (op *OperationRead) SingleRow(
@@ -1107,30 +1173,172 @@ func (op *OperationRead) SingleRow(
 		op.fields = allFieldsList
 	}
 
-	queryString := SelectQuery(op.fields)
-	queryString += " WHERE expense_id = $1 LIMIT 1"
+	return op.f.find(ctx, "SingleRow", op.executor(ctx), *op.pk, op.fields)
+}
 
-	var row *sql.Row
-	if op.tx != nil {
-		row = op.tx.QueryRowContext(ctx, queryString, op.pk.ExpenseID)
-	} else {
-		row = op.f.db.QueryRowContext(ctx, queryString, op.pk.ExpenseID)
+type OperationWrite struct {
+	f       *Facade
+	tx      m_options.Executor
+	creates []*Data
+	updates []updateOp
+	deletes []PrimaryKey
+	puts    []*Data
+}
+
+type updateOp struct {
+	pk      PrimaryKey
+	version int64 // 0 applies the update at any version
+	data    UpdateFields
+}
+
+func (op *OperationWrite) Create(data *Data) *OperationWrite {
+	op.creates = append(op.creates, data)
+	return op
+}
+
+func (op *OperationWrite) Put(data *Data) *OperationWrite {
+	op.puts = append(op.puts, data)
+	return op
+}
+
+func (op *OperationWrite) Update(
+	pk PrimaryKey,
+	update Updater,
+) *OperationWrite {
+	data := fieldsOf(update)
+	op.updates = append(op.updates, updateOp{
+		pk:      pk,
+		version: expectedVersion(data),
+		data:    data,
+	})
+	return op
+}
+
+// UpdateVersion queues an update that fails the batch with
+// m_errors.ErrStaleVersion unless the expense is at version.
+func (op *OperationWrite) UpdateVersion(
+	pk PrimaryKey,
+	version int64,
+	update Updater,
+) *OperationWrite {
+	op.updates = append(op.updates, updateOp{
+		pk:      pk,
+		version: version,
+		data:    fieldsOf(update),
+	})
+	return op
+}
+
+func (op *OperationWrite) Delete(
+	pk PrimaryKey,
+) *OperationWrite {
+	op.deletes = append(op.deletes, pk)
+	return op
+}
+
+// Tx makes Apply run inside tx. Apply then works in a savepoint, so a failing
+// batch is rolled back on its own and the outer transaction stays usable.
+func (op *OperationWrite) Tx(tx m_options.Executor) *OperationWrite {
+	op.tx = tx
+	return op
+}
+
+// validate checks every queued write before anything is sent, so an invalid
+// row fails the batch without a round trip.
+func (op *OperationWrite) validate() error {
+	for _, data := range op.creates {
+		if err := validateData(data); err != nil {
+			return err
+		}
 	}
+	for _, data := range op.puts {
+		if err := validateData(data); err != nil {
+			return err
+		}
+	}
+	for _, update := range op.updates {
+		if err := validateUpdate(update.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	var data Data
-	err := row.Scan(data.fieldPtrs(op.fields)...)
+// Apply runs every queued write atomically. Without an outer transaction it
+// begins one on the pool; inside a transaction (set with Tx, carried by the
+// context or bound with WithExecutor) it uses SAVEPOINT and RELEASE, and
+// ROLLBACK TO SAVEPOINT on failure.
+func (op *OperationWrite) Apply(ctx context.Context) error {
+	if err := op.validate(); err != nil {
+		return err
+	}
+
+	ex := op.tx
+	if ex == nil {
+		ex = op.f.executor(ctx)
+	}
+
+	tx, err := m_options.Begin(ctx, ex)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, err
-		}
-		op.f.logError("SingleRow", "Failed to Scan", logger.H{
-			"error":  err,
-			"fields": op.fields,
+		op.f.logError("OperationWrite Apply", "Failed to Begin transaction", logger.H{
+			"error": err,
 		})
-		return nil, err
+		return m_errors.Map(err)
 	}
+	defer tx.Rollback(ctx)
 
-	return &data, nil
+	// Set the audit actor once for the whole batch
+	if err := m_audit.Run(ctx, tx, op.apply); err != nil {
+		return m_errors.Map(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		op.f.logError("OperationWrite Apply", "Failed to Commit transaction", logger.H{
+			"error": err,
+		})
+		return m_errors.Map(err)
+	}
+
+	return nil
+}
+
+// apply runs the queued writes on tx.
+func (op *OperationWrite) apply(ctx context.Context, tx m_options.Executor) error {
+	// Execute creates
+	for _, data := range op.creates {
+		if err := op.f.CreateTx(ctx, tx, data); err != nil {
+			return m_errors.Map(err)
+		}
+	}
+
+	// Execute puts (upserts)
+	for _, data := range op.puts {
+		if err := op.f.CreateOrUpdateTx(ctx, tx, data); err != nil {
+			return m_errors.Map(err)
+		}
+	}
+
+	// Execute updates
+	for _, update := range op.updates {
+		if update.version != 0 {
+			if err := op.f.UpdateVersionTx(ctx, tx, update.pk, update.version, update.data); err != nil {
+				return m_errors.Map(err)
+			}
+			continue
+		}
+		if _, err := op.f.UpdateTx(ctx, tx, update.pk, update.data); err != nil {
+			return m_errors.Map(err)
+		}
+	}
+
+	// Execute deletes
+	for _, pk := range op.deletes {
+		if _, err := op.f.DeleteTx(ctx, tx, pk); err != nil {
+			return m_errors.Map(err)
+		}
+	}
+
+	return nil
 }
 
 func (f *Facade) Read() *OperationRead {
@@ -1138,3 +1346,9 @@ func (f *Facade) Read() *OperationRead {
 		f: f,
 	}
 }
+
+func (f *Facade) Write() *OperationWrite {
+	return &OperationWrite{
+		f: f,
+	}
+}
@@ -1,4 +1,5 @@
 -- PostgreSQL table definition for expenses
+-- Kept in sync with m_migrate/migrations; apply the schema with Model.Migrate or cmd/migrate.
 -- Create ENUM type for expense types
 CREATE TYPE expense_type_enum AS ENUM (
     'food',
@@ -16,22 +17,29 @@ CREATE TABLE IF NOT EXISTS expenses
 (
     expense_id     UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
     expense_name   VARCHAR(255) NOT NULL,
-    expense_amount DECIMAL(15, 2),
+    expense_amount DECIMAL(15, 2) CONSTRAINT expenses_expense_amount_positive CHECK (expense_amount > 0),
+    expense_currency CHAR(3) NOT NULL DEFAULT 'UAH' CHECK (expense_currency ~ '^[A-Z]{3}$'),
     expense_type   expense_type_enum,
     expense_date   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    created_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at     TIMESTAMP WITH TIME ZONE,
+    version        BIGINT NOT NULL DEFAULT 1
 );
 
 -- Create indexes for better query performance
 CREATE INDEX IF NOT EXISTS idx_expenses_date ON expenses(expense_date);
 CREATE INDEX IF NOT EXISTS idx_expenses_type ON expenses(expense_type);
 CREATE INDEX IF NOT EXISTS idx_expenses_amount ON expenses(expense_amount);
+CREATE INDEX IF NOT EXISTS idx_expenses_deleted_at ON expenses(deleted_at) WHERE deleted_at IS NOT NULL;
 
 -- Add comments for documentation
 COMMENT ON TABLE expenses IS 'Table to store expense records';
 COMMENT ON COLUMN expenses.expense_id IS 'Unique identifier for expense (UUID)';
 COMMENT ON COLUMN expenses.expense_name IS 'Name/description of the expense';
 COMMENT ON COLUMN expenses.expense_amount IS 'Amount of the expense';
+COMMENT ON COLUMN expenses.expense_currency IS 'ISO 4217 currency of the expense amount';
 COMMENT ON COLUMN expenses.expense_type IS 'Category/type of expense';
 COMMENT ON COLUMN expenses.expense_date IS 'Date when expense occurred';
 COMMENT ON COLUMN expenses.created_at IS 'Record creation timestamp';
+COMMENT ON COLUMN expenses.deleted_at IS 'Soft delete timestamp, NULL while the record is live';
+COMMENT ON COLUMN expenses.version IS 'Row version, incremented on every write';
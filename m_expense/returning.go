@@ -0,0 +1,261 @@
+package m_expense
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rsmrtk/db-fd-model/m_audit"
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// The Returning variants give back the rows as stored, read in the same
+// statement through RETURNING. fields selects the columns to return; nil
+// returns all of them.
+
+// returning renders the RETURNING clause for fields.
+func returning(fields []Field) string {
+	return " RETURNING " + strings.Join(makeStringFields(fields), ", ")
+}
+
+func returningFields(fields []Field) []Field {
+	if len(fields) == 0 {
+		return allFieldsList
+	}
+	return fields
+}
+
+// CreateReturning is like Create but returns the inserted expense.
+func (f *Facade) CreateReturning(
+	ctx context.Context,
+	data *Data,
+	fields []Field,
+) (*Data, error) {
+	return f.CreateReturningTx(ctx, f.executor(ctx), data, fields)
+}
+
+func (f *Facade) CreateReturningTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	data *Data,
+	fields []Field,
+) (*Data, error) {
+	if err := validateData(data); err != nil {
+		return nil, err
+	}
+	f.generate(data)
+
+	fields = returningFields(fields)
+	query, args := insertQuery(data)
+	return f.queryRow(ctx, "CreateReturningTx", tx, fields, query+returning(fields), args...)
+}
+
+// CreateOrUpdateReturning is like CreateOrUpdate but returns the expense as
+// inserted or overwritten.
+func (f *Facade) CreateOrUpdateReturning(
+	ctx context.Context,
+	data *Data,
+	fields []Field,
+) (*Data, error) {
+	return f.CreateOrUpdateReturningTx(ctx, f.executor(ctx), data, fields)
+}
+
+func (f *Facade) CreateOrUpdateReturningTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	data *Data,
+	fields []Field,
+) (*Data, error) {
+	if err := validateData(data); err != nil {
+		return nil, err
+	}
+	f.generate(data)
+	defaultCurrency(data)
+	defaultVersion(data)
+
+	fields = returningFields(fields)
+	query, _, _ := upsertQuery(Upsert{}) // The zero Upsert always renders
+	return f.queryRow(ctx, "CreateOrUpdateReturningTx", tx, fields, query+returning(fields), GetValues(data)...)
+}
+
+// UpdateReturning is like Update but returns the updated expense, or an error
+// matching m_errors.ErrNotFound when no expense matched.
+func (f *Facade) UpdateReturning(
+	ctx context.Context,
+	pk PrimaryKey,
+	update Updater,
+	fields []Field,
+) (*Data, error) {
+	return f.UpdateReturningTx(ctx, f.executor(ctx), pk, update, fields)
+}
+
+func (f *Facade) UpdateReturningTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	pk PrimaryKey,
+	update Updater,
+	fields []Field,
+) (*Data, error) {
+	data := fieldsOf(update)
+	if version := expectedVersion(data); version != 0 {
+		return f.UpdateVersionReturningTx(ctx, tx, pk, version, data, fields)
+	}
+	fields = returningFields(fields)
+	if len(data) == 0 {
+		return f.find(ctx, "UpdateReturningTx", tx, pk, fields)
+	}
+	if err := validateUpdate(data); err != nil {
+		return nil, err
+	}
+
+	query, args := updateQuery(pk, data)
+	return f.queryRow(ctx, "UpdateReturningTx", tx, fields, f.andScope(query)+returning(fields), args...)
+}
+
+// UpdateByParamsReturning is like UpdateByParams but returns the updated
+// expenses; the slice is empty when nothing matched.
+func (f *Facade) UpdateByParamsReturning(
+	ctx context.Context,
+	queryParams []QueryParam,
+	update Updater,
+	fields []Field,
+) ([]*Data, error) {
+	return f.UpdateByParamsReturningTx(ctx, f.executor(ctx), queryParams, update, fields)
+}
+
+func (f *Facade) UpdateByParamsReturningTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	queryParams []QueryParam,
+	update Updater,
+	fields []Field,
+) ([]*Data, error) {
+	data := fieldsOf(update)
+	fields = returningFields(fields)
+	if len(data) == 0 {
+		return f.get(ctx, "UpdateByParamsReturningTx", tx, queryParams, fields)
+	}
+	if err := validateUpdate(data); err != nil {
+		return nil, err
+	}
+
+	query, args := updateByParamsQuery(f.scoped(queryParams), data)
+	return f.queryRows(ctx, "UpdateByParamsReturningTx", tx, fields, query+returning(fields), args...)
+}
+
+// DeleteReturning is like Delete but returns the deleted expense, or an error
+// matching m_errors.ErrNotFound when no expense matched.
+func (f *Facade) DeleteReturning(
+	ctx context.Context,
+	pk PrimaryKey,
+	fields []Field,
+) (*Data, error) {
+	return f.DeleteReturningTx(ctx, f.executor(ctx), pk, fields)
+}
+
+func (f *Facade) DeleteReturningTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	pk PrimaryKey,
+	fields []Field,
+) (*Data, error) {
+	fields = returningFields(fields)
+	query := fmt.Sprintf("DELETE FROM %s WHERE expense_id = $1", Table)
+	args := []interface{}{pk.ExpenseID}
+	if f.softDelete {
+		query, args = f.softDeleteQuery(pk)
+	}
+	return f.queryRow(ctx, "DeleteReturningTx", tx, fields, query+returning(fields), args...)
+}
+
+// queryRow runs a write with the actor from ctx set for the audit trigger
+// and scans the single row it returns into a new Data.
+func (f *Facade) queryRow(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	fields []Field,
+	query string,
+	args ...interface{},
+) (*Data, error) {
+	var data Data
+	err := m_audit.Run(ctx, ex, func(ctx context.Context, ex m_options.Executor) error {
+		return data.scan(ex.QueryRow(ctx, query, args...), fields)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, m_errors.Map(err)
+		}
+		f.logError(functionName, "Failed to QueryRow", logger.H{
+			"error":  err,
+			"fields": fields,
+		})
+		return nil, m_errors.Map(err)
+	}
+	return &data, nil
+}
+
+// queryRows runs a write with the actor from ctx set for the audit trigger
+// and scans every row it returns.
+func (f *Facade) queryRows(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	fields []Field,
+	query string,
+	args ...interface{},
+) ([]*Data, error) {
+	var res []*Data
+	err := m_audit.Run(ctx, ex, func(ctx context.Context, ex m_options.Executor) error {
+		var err error
+		res, err = f.scanRows(ctx, functionName, ex, fields, query, args...)
+		return err
+	})
+	if err != nil {
+		return nil, m_errors.Map(err)
+	}
+	return res, nil
+}
+
+func (f *Facade) scanRows(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	fields []Field,
+	query string,
+	args ...interface{},
+) ([]*Data, error) {
+	rows, err := ex.Query(ctx, query, args...)
+	if err != nil {
+		f.logError(functionName, "Failed to Query", logger.H{
+			"error":  err,
+			"fields": fields,
+		})
+		return nil, m_errors.Map(err)
+	}
+	defer rows.Close()
+
+	res := make([]*Data, 0)
+	for rows.Next() {
+		var data Data
+		if err := data.scan(rows, fields); err != nil {
+			f.logError(functionName, "Failed to Scan", logger.H{
+				"error":  err,
+				"fields": fields,
+			})
+			return nil, m_errors.Map(err)
+		}
+		res = append(res, &data)
+	}
+	if err := rows.Err(); err != nil {
+		f.logError(functionName, "Failed to iterate rows", logger.H{
+			"error": err,
+		})
+		return nil, m_errors.Map(err)
+	}
+	return res, nil
+}
@@ -0,0 +1,117 @@
+package m_expense
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// Soft delete is enabled per table with m_options.Options.SoftDelete. Delete
+// then sets deleted_at instead of removing the row, and reads and updates
+// skip rows where it is set, unless the facade comes from IncludeDeleted.
+// Queries passed as raw SQL are not rewritten.
+
+// SoftDeletes reports whether Delete sets deleted_at instead of removing
+// the row.
+func (f *Facade) SoftDeletes() bool {
+	return f.softDelete
+}
+
+// IncludeDeleted returns a copy of the facade whose reads and updates also
+// see soft-deleted expenses.
+func (f *Facade) IncludeDeleted() *Facade {
+	bound := *f
+	bound.includeDeleted = true
+	return &bound
+}
+
+// scope returns the conditions added to every read and update.
+func (f *Facade) scope() []string {
+	if !f.softDelete || f.includeDeleted {
+		return nil
+	}
+	return []string{fmt.Sprintf(`"%s" IS NULL`, DeletedAt)}
+}
+
+// andScope appends the scope to a query that ends in a WHERE clause.
+func (f *Facade) andScope(query string) string {
+	for _, condition := range f.scope() {
+		query += " AND " + condition
+	}
+	return query
+}
+
+// scoped returns queryParams with the scope added.
+func (f *Facade) scoped(queryParams []QueryParam) []QueryParam {
+	if len(f.scope()) == 0 {
+		return queryParams
+	}
+	return append(queryParams[:len(queryParams):len(queryParams)], QueryParam{
+		Field: DeletedAt, Operator: OpIs, Value: nil,
+	})
+}
+
+// softDeleteQuery marks the expense with pk as deleted now.
+func (f *Facade) softDeleteQuery(pk PrimaryKey) (string, []interface{}) {
+	query := fmt.Sprintf("UPDATE %s SET %s = $2, %s = %s + 1 WHERE %s = $1 AND %s IS NULL",
+		Table, DeletedAt, Version, Version, ExpenseID, DeletedAt)
+	return query, []interface{}{pk.ExpenseID, f.now()}
+}
+
+// Restore clears deleted_at on the expense with pk and returns the
+// number of rows affected, 0 when it was not deleted.
+func (f *Facade) Restore(
+	ctx context.Context,
+	pk PrimaryKey,
+) (int64, error) {
+	return f.RestoreTx(ctx, f.executor(ctx), pk)
+}
+
+func (f *Facade) RestoreTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	pk PrimaryKey,
+) (int64, error) {
+	query := fmt.Sprintf("UPDATE %s SET %s = NULL, %s = %s + 1 WHERE %s = $1 AND %s IS NOT NULL",
+		Table, DeletedAt, Version, Version, ExpenseID, DeletedAt)
+
+	tag, err := f.exec(ctx, tx, query, pk.ExpenseID)
+	if err != nil {
+		f.logError("RestoreTx", "Failed to execute", logger.H{
+			"error": err, "primaryKey": pk,
+		})
+		return 0, m_errors.Map(err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Purge permanently removes expenses deleted more than olderThan ago and
+// returns the number of rows removed.
+func (f *Facade) Purge(
+	ctx context.Context,
+	olderThan time.Duration,
+) (int64, error) {
+	return f.PurgeTx(ctx, f.executor(ctx), olderThan)
+}
+
+func (f *Facade) PurgeTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	olderThan time.Duration,
+) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s < $1", Table, DeletedAt)
+	cutoff := f.now().Add(-olderThan)
+
+	tag, err := f.exec(ctx, tx, query, cutoff)
+	if err != nil {
+		f.logError("PurgeTx", "Failed to execute", logger.H{
+			"error": err, "cutoff": cutoff,
+		})
+		return 0, m_errors.Map(err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,140 @@
+package m_expense
+
+import (
+	"context"
+	"time"
+
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/db-fd-model/m_types"
+)
+
+// Data read from the database remembers the values it was read with, so
+// Save can send only the columns changed since. Diff compares two Data
+// built by the caller.
+
+// rowScanner is implemented by pgx.Row and pgx.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scan reads fields from row into data and remembers them for Save.
+func (data *Data) scan(row rowScanner, fields []Field) error {
+	if err := row.Scan(data.fieldPtrs(fields)...); err != nil {
+		return err
+	}
+	original := data.clone()
+	data.original = &original
+	return nil
+}
+
+// clone copies data, including the values its pointers refer to, without
+// the remembered original.
+func (data *Data) clone() Data {
+	c := *data
+	c.original = nil
+	c.ExpenseName = clonePtr(data.ExpenseName)
+	c.ExpenseAmount = clonePtr(data.ExpenseAmount)
+	c.ExpenseCurrency = clonePtr(data.ExpenseCurrency)
+	c.ExpenseType = clonePtr(data.ExpenseType)
+	c.ExpenseDate = clonePtr(data.ExpenseDate)
+	c.CreatedAt = clonePtr(data.CreatedAt)
+	c.DeletedAt = clonePtr(data.DeletedAt)
+	return c
+}
+
+// Changes returns the columns changed since data was read. Columns the read
+// did not select count as changed once set. For Data that was not read from
+// the database it returns every column that is set.
+func (data *Data) Changes() UpdateFields {
+	if data.original == nil {
+		return Diff(&Data{}, data)
+	}
+	return Diff(data.original, data)
+}
+
+// Diff returns the columns whose value differs between old and new, set to
+// their value in new. The primary key and Version are never included.
+func Diff(old, new *Data) UpdateFields {
+	diff := make(UpdateFields)
+	if !same(old.ExpenseName, new.ExpenseName, eq[string]) {
+		diff[ExpenseName] = new.ExpenseName
+	}
+	if !same(old.ExpenseAmount, new.ExpenseAmount, m_types.Money.Equal) {
+		diff[ExpenseAmount] = new.ExpenseAmount
+	}
+	if !same(old.ExpenseCurrency, new.ExpenseCurrency, eq[m_types.Currency]) {
+		diff[ExpenseCurrency] = new.ExpenseCurrency
+	}
+	if !same(old.ExpenseType, new.ExpenseType, eq[EnumType]) {
+		diff[ExpenseType] = new.ExpenseType
+	}
+	if !same(old.ExpenseDate, new.ExpenseDate, time.Time.Equal) {
+		diff[ExpenseDate] = new.ExpenseDate
+	}
+	if !same(old.CreatedAt, new.CreatedAt, time.Time.Equal) {
+		diff[CreatedAt] = new.CreatedAt
+	}
+	if !same(old.DeletedAt, new.DeletedAt, time.Time.Equal) {
+		diff[DeletedAt] = new.DeletedAt
+	}
+	return diff
+}
+
+// Save updates the columns of data changed since it was read, see Changes,
+// and refreshes data with the stored row. When data was read with its
+// Version, the update only applies at that version and otherwise fails with
+// m_errors.ErrStaleVersion. Save does nothing when no column changed, and
+// returns an error matching m_errors.ErrNotFound when the expense is gone.
+func (f *Facade) Save(
+	ctx context.Context,
+	data *Data,
+) error {
+	return f.SaveTx(ctx, f.executor(ctx), data)
+}
+
+func (f *Facade) SaveTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	data *Data,
+) error {
+	diff := data.Changes()
+	if len(diff) == 0 {
+		return nil
+	}
+
+	var (
+		saved *Data
+		err   error
+	)
+	if data.original != nil && data.original.Version != 0 {
+		saved, err = f.UpdateVersionReturningTx(ctx, tx, PrimaryKey{ExpenseID: data.ExpenseID}, data.original.Version, diff, nil)
+	} else {
+		saved, err = f.UpdateReturningTx(ctx, tx, PrimaryKey{ExpenseID: data.ExpenseID}, diff, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	*data = *saved
+	return nil
+}
+
+// same reports whether a and b are both NULL or hold equal values.
+func same[T any](a, b *T, equal func(T, T) bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return equal(*a, *b)
+}
+
+func eq[T comparable](a, b T) bool {
+	return a == b
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,45 @@
+package m_expense
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rsmrtk/db-fd-model/m_types"
+)
+
+func TestUpsertQueryKeepsCreatedAndDeletedAt(t *testing.T) {
+	query, _, err := upsertQuery(Upsert{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, column := range []Field{CreatedAt, DeletedAt} {
+		if strings.Contains(query, string(column)+" = EXCLUDED") {
+			t.Errorf("default upsert overwrites %s: %s", column, query)
+		}
+	}
+
+	query, _, err = upsertQuery(Upsert{Update: []Field{DeletedAt}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(query, "deleted_at = EXCLUDED.deleted_at") {
+		t.Errorf("explicit Update does not overwrite deleted_at: %s", query)
+	}
+}
+
+func TestUpsertQueryWhereIn(t *testing.T) {
+	ids := []m_types.UUID{m_types.NewUUIDv7(), m_types.NewUUIDv7()}
+	query, args, err := upsertQuery(Upsert{Where: []QueryParam{
+		{Field: ExpenseID, Operator: OpIn, Value: ids},
+		{Field: ExpenseType, Operator: OpIn, Value: []string{"food"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(query, `expenses."expense_id" = ANY($10) AND expenses."expense_type" = ANY($11)`) {
+		t.Errorf("unexpected WHERE: %s", query)
+	}
+	if len(args) != 2 {
+		t.Errorf("got %d args, want 2", len(args))
+	}
+}
@@ -0,0 +1,64 @@
+package m_expense
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/rsmrtk/db-fd-model/m_validate"
+	"github.com/rsmrtk/db-fd-model/sql_builder"
+)
+
+// Validator holds the rules every write is checked against before it
+// reaches the database. Extend it with Add, e.g.
+//
+//	m_expense.Validator.Add(m_expense.ExpenseName, m_validate.Custom("no_digits", check))
+var Validator = m_validate.New[Field](Table).
+	Add(ExpenseName, m_validate.Required(), m_validate.MaxLen(255)).
+	Add(ExpenseAmount, m_validate.Required(), m_validate.Positive()).
+	Add(ExpenseCurrency, m_validate.Valid("currency")).
+	Add(ExpenseType, m_validate.Enum(GetAllEnumTypes()...))
+
+// validateData checks a full row before it is inserted.
+func validateData(data *Data) error {
+	return Validator.Validate(data.Map())
+}
+
+// noExpr are the columns an expression may not set: the facade keeps the
+// primary key and Version, Create sets created_at and Delete deleted_at.
+var noExpr = []Field{ExpenseID, Version, CreatedAt, DeletedAt}
+
+// validateUpdate checks only the fields being set. The literal a Coalesce
+// or IfNull may store is checked like a plain value, unless it is NULL and
+// keeps the column as it is. Arithmetic and DEFAULT are computed by the
+// database and left to its constraints, e.g. the positive amount CHECK.
+func validateUpdate(data UpdateFields) error {
+	values := data.Map()
+	var protected []m_validate.Violation
+	for column, value := range values {
+		expr, ok := value.(sql_builder.Expr)
+		if !ok {
+			continue
+		}
+		if slices.Contains(noExpr, Field(column)) {
+			protected = append(protected, m_validate.Violation{
+				Field:   column,
+				Rule:    "no_expr",
+				Message: "cannot be set to an expression",
+			})
+			continue
+		}
+		literal, ok := expr.Value()
+		if _, notNull := m_validate.Deref(literal); ok && notNull {
+			values[column] = literal
+		} else {
+			delete(values, column)
+		}
+	}
+	if len(protected) > 0 {
+		slices.SortFunc(protected, func(a, b m_validate.Violation) int {
+			return strings.Compare(a.Field, b.Field)
+		})
+		return &m_validate.ValidationError{Table: Table, Violations: protected}
+	}
+	return Validator.Validate(values)
+}
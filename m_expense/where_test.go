@@ -0,0 +1,25 @@
+package m_expense
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rsmrtk/db-fd-model/m_types"
+)
+
+func TestConstructWhereClauseIn(t *testing.T) {
+	ids := []m_types.UUID{m_types.NewUUIDv7(), m_types.NewUUIDv7()}
+	where, args := constructWhereClause([]QueryParam{
+		{Field: ExpenseID, Operator: OpIn, Value: ids},
+		{Field: DeletedAt, Operator: OpIs, Value: nil},
+		{Field: ExpenseType, Operator: OpIn, Value: []string{"food", "others"}},
+	}, 3)
+
+	want := `"expense_id" = ANY($3) AND "deleted_at" IS NULL AND "expense_type" = ANY($4)`
+	if where != want {
+		t.Errorf("got %s, want %s", where, want)
+	}
+	if len(args) != 2 || !reflect.DeepEqual(args[0], ids) {
+		t.Errorf("unexpected args %v", args)
+	}
+}
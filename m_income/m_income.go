@@ -2,6 +2,7 @@ package m_income
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -9,7 +10,11 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmrtk/db-fd-model/m_audit"
+	"github.com/rsmrtk/db-fd-model/m_errors"
 	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/db-fd-model/m_schema"
+	"github.com/rsmrtk/db-fd-model/m_types"
 	"github.com/rsmrtk/db-fd-model/sql_builder"
 	"github.com/rsmrtk/smartlg/logger"
 )
@@ -24,7 +29,13 @@ const (
 type Facade struct {
 	log *logger.Logger
 	db  *pgxpool.Pool
+	ex  m_options.Executor
 	//
+	router *m_options.Router
+	clock  func() time.Time
+
+	softDelete     bool
+	includeDeleted bool
 }
 
 func New(o *m_options.Options) *Facade {
@@ -32,6 +43,10 @@ func New(o *m_options.Options) *Facade {
 		log: o.Log,
 		db:  o.DB,
 		//
+		router: o.Router,
+		clock:  o.Clock,
+
+		softDelete: o.SoftDeletes(Table),
 	}
 }
 
@@ -39,13 +54,53 @@ func (f *Facade) logError(functionName string, msg string, h logger.H) {
 	f.log.Error(fmt.Sprintf("[%s.%s - %s] %s", Package, functionName, Table, msg), h)
 }
 
+// WithExecutor returns a copy of the facade bound to ex, e.g. a pgx.Tx
+// shared with other facades.
+func (f *Facade) WithExecutor(ex m_options.Executor) *Facade {
+	bound := *f
+	bound.ex = ex
+	return &bound
+}
+
+// executor returns the bound executor, the executor carried by ctx, or the
+// shared pool.
+func (f *Facade) executor(ctx context.Context) m_options.Executor {
+	if f.ex != nil {
+		return f.ex
+	}
+	if ex, ok := m_options.ExecutorFromContext(ctx); ok {
+		return ex
+	}
+	return f.db
+}
+
+// reader is like executor, but sends reads to a replica when replicas are
+// configured and ctx was not marked with m_options.WithPrimary.
+func (f *Facade) reader(ctx context.Context) m_options.Executor {
+	if f.ex != nil {
+		return f.ex
+	}
+	if ex, ok := m_options.ExecutorFromContext(ctx); ok {
+		return ex
+	}
+	if f.router != nil && !m_options.UsePrimary(ctx) {
+		return f.router.Reader()
+	}
+	return f.db
+}
+
 type Data struct {
-	IncomeID     string
-	IncomeName   *string
-	IncomeAmount *float64
-	IncomeType   *string
-	IncomeDate   *time.Time
-	CreatedAt    *time.Time
+	IncomeID       string
+	IncomeName     *string
+	IncomeAmount   *m_types.Money
+	IncomeCurrency *m_types.Currency
+	IncomeType     *string
+	IncomeDate     *time.Time
+	CreatedAt      *time.Time
+	DeletedAt      *time.Time
+	Version        int64 // Incremented by every write, see UpdateVersion
+
+	original *Data // As read from the database, see Save
 }
 
 func (data *Data) Map() map[string]any {
@@ -53,21 +108,27 @@ func (data *Data) Map() map[string]any {
 	out[string(IncomeID)] = data.IncomeID
 	out[string(IncomeName)] = data.IncomeName
 	out[string(IncomeAmount)] = data.IncomeAmount
+	out[string(IncomeCurrency)] = data.IncomeCurrency
 	out[string(IncomeType)] = data.IncomeType
 	out[string(IncomeDate)] = data.IncomeDate
 	out[string(CreatedAt)] = data.CreatedAt
+	out[string(DeletedAt)] = data.DeletedAt
+	out[string(Version)] = data.Version
 	return out
 }
 
 type Field string
 
 const (
-	IncomeID     Field = "income_id"
-	IncomeName   Field = "income_name"
-	IncomeAmount Field = "income_amount"
-	IncomeType   Field = "income_type"
-	IncomeDate   Field = "income_date"
-	CreatedAt    Field = "created_at"
+	IncomeID       Field = "income_id"
+	IncomeName     Field = "income_name"
+	IncomeAmount   Field = "income_amount"
+	IncomeCurrency Field = "income_currency"
+	IncomeType     Field = "income_type"
+	IncomeDate     Field = "income_date"
+	CreatedAt      Field = "created_at"
+	DeletedAt      Field = "deleted_at"
+	Version        Field = "version"
 )
 
 type EnumType string
@@ -95,9 +156,12 @@ func GetAllFields() []Field {
 		IncomeID,
 		IncomeName,
 		IncomeAmount,
+		IncomeCurrency,
 		IncomeType,
 		IncomeDate,
 		CreatedAt,
+		DeletedAt,
+		Version,
 	}
 }
 
@@ -108,12 +172,15 @@ func (f Field) String() string {
 }
 
 var fieldsMap = map[Field]func(data *Data) interface{}{
-	IncomeID:     func(data *Data) interface{} { return &data.IncomeID },
-	IncomeName:   func(data *Data) interface{} { return &data.IncomeName },
-	IncomeAmount: func(data *Data) interface{} { return &data.IncomeAmount },
-	IncomeType:   func(data *Data) interface{} { return &data.IncomeType },
-	IncomeDate:   func(data *Data) interface{} { return &data.IncomeDate },
-	CreatedAt:    func(data *Data) interface{} { return &data.CreatedAt },
+	IncomeID:       func(data *Data) interface{} { return &data.IncomeID },
+	IncomeName:     func(data *Data) interface{} { return &data.IncomeName },
+	IncomeAmount:   func(data *Data) interface{} { return &data.IncomeAmount },
+	IncomeCurrency: func(data *Data) interface{} { return &data.IncomeCurrency },
+	IncomeType:     func(data *Data) interface{} { return &data.IncomeType },
+	IncomeDate:     func(data *Data) interface{} { return &data.IncomeDate },
+	CreatedAt:      func(data *Data) interface{} { return &data.CreatedAt },
+	DeletedAt:      func(data *Data) interface{} { return &data.DeletedAt },
+	Version:        func(data *Data) interface{} { return &data.Version },
 }
 
 func (data *Data) fieldPtrs(fields []Field) []interface{} {
@@ -134,22 +201,47 @@ func GetColumns() []string {
 		IncomeID.String(),
 		IncomeName.String(),
 		IncomeAmount.String(),
+		IncomeCurrency.String(),
 		IncomeType.String(),
 		IncomeDate.String(),
 		CreatedAt.String(),
+		DeletedAt.String(),
+		Version.String(),
 	}
 }
 
 var allStringFields = GetColumns()
 
+// GetSchema describes how Data maps the incomes table, for drift checks.
+func GetSchema() m_schema.Table {
+	return m_schema.Table{
+		Name: Table,
+		Columns: []m_schema.Column{
+			{Name: IncomeID.String(), GoType: "string", PgTypes: []string{"uuid", "character varying", "text"}},
+			{Name: IncomeName.String(), GoType: "*string", PgTypes: []string{"character varying", "text"}, Nullable: true},
+			{Name: IncomeAmount.String(), GoType: "*m_types.Money", PgTypes: []string{"numeric"}, Nullable: true},
+			{Name: IncomeCurrency.String(), GoType: "*m_types.Currency", PgTypes: []string{"character", "character varying", "text"}, Nullable: true},
+			{Name: IncomeType.String(), GoType: "*string", PgTypes: []string{"USER-DEFINED"}, Nullable: true,
+				Enum: []string{EnumTypeSalary.String(), EnumTypeTransfer.String(), EnumTypeOthers.String()}},
+			{Name: IncomeDate.String(), GoType: "*time.Time", PgTypes: []string{"timestamp with time zone", "timestamp without time zone", "date"}, Nullable: true},
+			{Name: CreatedAt.String(), GoType: "*time.Time", PgTypes: []string{"timestamp with time zone", "timestamp without time zone"}, Nullable: true},
+			{Name: DeletedAt.String(), GoType: "*time.Time", PgTypes: []string{"timestamp with time zone", "timestamp without time zone"}, Nullable: true},
+			{Name: Version.String(), GoType: "int64", PgTypes: []string{"bigint", "integer"}},
+		},
+	}
+}
+
 func GetValues(data *Data) []interface{} {
 	return []interface{}{
 		data.IncomeID,
 		data.IncomeName,
 		data.IncomeAmount,
+		data.IncomeCurrency,
 		data.IncomeType,
 		data.IncomeDate,
 		data.CreatedAt,
+		data.DeletedAt,
+		data.Version,
 	}
 }
 
@@ -208,6 +300,13 @@ func SelectQuery(fields []Field) string {
 }
 
 func ConstructWhereClause(queryParams []QueryParam) (whereClause string, params map[string]interface{}) {
+	return constructWhereClause(queryParams, 1)
+}
+
+// constructWhereClause numbers placeholders from paramCounter so the clause
+// can follow other parameters, e.g. the SET list of an UPDATE. params keeps
+// its param0, param1, ... names whatever the first placeholder is.
+func constructWhereClause(queryParams []QueryParam, paramCounter int) (whereClause string, params map[string]interface{}) {
 	whereClauses := make([]string, len(queryParams))
 	params = make(map[string]interface{}, len(queryParams))
 	builder := strings.Builder{}
@@ -216,18 +315,19 @@ func ConstructWhereClause(queryParams []QueryParam) (whereClause string, params
 			whereClauses[i] = fmt.Sprintf("%s %s NULL", qp.Field, qp.Operator)
 			continue
 		}
+		// Number params by value, so IS NULL clauses do not leave gaps
 		builder.WriteString("param")
-		builder.WriteString(strconv.Itoa(i))
+		builder.WriteString(strconv.Itoa(len(params)))
 		paramName := builder.String()
 		builder.Reset()
 
 		// Construct param - PostgreSQL uses $N syntax
 		builder.WriteString("$")
-		builder.WriteString(strconv.Itoa(i + 1))
+		builder.WriteString(strconv.Itoa(len(params) + paramCounter))
 		param := builder.String()
 		builder.Reset()
 
-		if qp.Operator == "IN" {
+		if qp.Operator == OpIn {
 			// PostgreSQL uses = ANY($N) instead of IN UNNEST(@param)
 			builder.WriteString("= ANY(")
 			builder.WriteString(param)
@@ -239,8 +339,10 @@ func ConstructWhereClause(queryParams []QueryParam) (whereClause string, params
 		// Construct whereClause
 		builder.WriteString(string(qp.Field))
 		builder.WriteString(" ")
-		builder.WriteString(string(qp.Operator))
-		builder.WriteString(" ")
+		if qp.Operator != OpIn {
+			builder.WriteString(string(qp.Operator))
+			builder.WriteString(" ")
+		}
 		builder.WriteString(param)
 		whereClause := builder.String()
 		whereClauses[i] = whereClause
@@ -251,76 +353,61 @@ func ConstructWhereClause(queryParams []QueryParam) (whereClause string, params
 	return strings.Join(whereClauses, " AND "), params
 }
 
+// CreateOrUpdate inserts data or overwrites the income with the same id,
+// keeping its created_at and deleted_at. Upsert takes other conflict
+// targets and policies.
 func (f *Facade) CreateOrUpdate(
 	ctx context.Context,
 	data *Data,
 ) error {
-	query := fmt.Sprintf(`
-		INSERT INTO %s (%s)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (%s) DO UPDATE SET
-			%s = EXCLUDED.%s,
-			%s = EXCLUDED.%s,
-			%s = EXCLUDED.%s,
-			%s = EXCLUDED.%s,
-			%s = EXCLUDED.%s
-	`, Table,
-		strings.Join(allStringFields, ", "),
-		IncomeID,
-		IncomeName, IncomeName,
-		IncomeAmount, IncomeAmount,
-		IncomeType, IncomeType,
-		IncomeDate, IncomeDate,
-		CreatedAt, CreatedAt,
-	)
-
-	_, err := f.db.Exec(ctx, query, GetValues(data)...)
+	return f.CreateOrUpdateTx(ctx, f.executor(ctx), data)
+}
+
+func (f *Facade) CreateOrUpdateTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	data *Data,
+) error {
+	if err := validateData(data); err != nil {
+		return err
+	}
+	f.generate(data)
+	defaultCurrency(data)
+	defaultVersion(data)
+
+	query, _, _ := upsertQuery(Upsert{}) // The zero Upsert always renders
+	_, err := f.exec(ctx, tx, query, GetValues(data)...)
 	if err != nil {
 		f.logError("CreateOrUpdate", "Failed to Exec", logger.H{
 			"error": err,
 			"data":  data,
 		})
-		return err
+		return m_errors.Map(err)
 	}
 
 	return nil
 }
 
+// Create inserts data. A missing id is generated as a UUIDv7 and a missing
+// created_at is taken from the clock; columns left nil get their database
+// defaults. data is then filled with the row as stored.
 func (f *Facade) Create(ctx context.Context, data *Data) error {
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6)",
-		Table, strings.Join(allStringFields, ", "))
-
-	_, err := f.db.Exec(ctx, query, GetValues(data)...)
-	if err != nil {
-		f.logError("Create", "Failed to Exec", logger.H{
-			"error": err,
-			"data":  data,
-		})
-		return err
-	}
-
-	return nil
+	return f.CreateTx(ctx, f.executor(ctx), data)
 }
 
 func (f *Facade) Exists(
 	ctx context.Context,
 	incomeID string,
-) bool {
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1", ID, Table, ID)
-	var id string
-	err := f.db.QueryRow(ctx, query, incomeID).Scan(&id)
-	return err == nil
+) (bool, error) {
+	return f.ExistTx(ctx, f.reader(ctx), incomeID)
 }
 
 func (f *Facade) ExistsRtx(
 	ctx context.Context,
-	tx pgx.Tx,
+	rtx m_options.Executor,
 	incomeID string,
-) bool {
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1", ID, Table, ID)
-	var id string
-	err := tx.QueryRow(ctx, query, incomeID).Scan(&id)
-	return err == nil
+) (bool, error) {
+	return f.ExistTx(ctx, rtx, incomeID)
 }
 
 func (f *Facade) Get(
@@ -328,58 +415,46 @@ func (f *Facade) Get(
 	queryParams []QueryParam,
 	fields []Field,
 ) ([]*Data, error) {
-	// Construct SQL query
-	queryString := SelectQuery(fields)
-	whereClauses, params := ConstructWhereClause(queryParams)
-	if len(queryParams) > 0 {
-		queryString += " WHERE " + whereClauses
-	}
-
-	// Convert map params to ordered slice
-	args := make([]interface{}, len(params))
-	for i := 0; i < len(params); i++ {
-		paramName := fmt.Sprintf("param%d", i)
-		args[i] = params[paramName]
-	}
+	return f.get(ctx, "Get", f.reader(ctx), queryParams, fields)
+}
 
-	rows, err := f.db.Query(ctx, queryString, args...)
-	if err != nil {
-		f.logError("Get", "Failed to Query", logger.H{
-			"error":        err,
-			"query_params": queryParams,
-			"fields":       fields,
-		})
-		return nil, err
-	}
-	defer rows.Close()
+func (f *Facade) GetRtx(
+	ctx context.Context,
+	rtx m_options.Executor,
+	queryParams []QueryParam,
+	fields []Field,
+) ([]*Data, error) {
+	return f.get(ctx, "GetRtx", rtx, queryParams, fields)
+}
 
+func (f *Facade) get(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	queryParams []QueryParam,
+	fields []Field,
+) ([]*Data, error) {
 	var res []*Data
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("Get", "Failed to Scan", logger.H{
-				"error":        err,
-				"query_params": queryParams,
-				"fields":       fields,
-			})
-			return nil, err
-		}
-		res = append(res, &data)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
+	err := f.getIter(ctx, functionName, ex, queryParams, fields, func(data *Data) {
+		res = append(res, data)
+	})
+	if err != nil {
+		return nil, m_errors.Map(err)
 	}
 
 	return res, nil
 }
 
-func (f *Facade) GetRtx(
+func (f *Facade) getIter(
 	ctx context.Context,
-	rtx pgx.Tx,
+	functionName string,
+	ex m_options.Executor,
 	queryParams []QueryParam,
 	fields []Field,
-) ([]*Data, error) {
+	callback func(*Data),
+) error {
+	queryParams = f.scoped(queryParams)
+
 	// Construct SQL query
 	queryString := SelectQuery(fields)
 	whereClauses, params := ConstructWhereClause(queryParams)
@@ -394,36 +469,39 @@ func (f *Facade) GetRtx(
 		args[i] = params[paramName]
 	}
 
-	rows, err := rtx.Query(ctx, queryString, args...)
+	if len(fields) == 0 {
+		fields = allFieldsList
+	}
+
+	rows, err := ex.Query(ctx, queryString, args...)
 	if err != nil {
-		f.logError("GetRtx", "Failed to Query", logger.H{
+		f.logError(functionName, "Failed to Query", logger.H{
 			"error":        err,
 			"query_params": queryParams,
 			"fields":       fields,
 		})
-		return nil, err
+		return m_errors.Map(err)
 	}
 	defer rows.Close()
 
-	var res []*Data
 	for rows.Next() {
 		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetRtx", "Failed to Scan", logger.H{
+		if err := data.scan(rows, fields); err != nil {
+			f.logError(functionName, "Failed to Scan", logger.H{
 				"error":        err,
 				"query_params": queryParams,
 				"fields":       fields,
 			})
-			return nil, err
+			return m_errors.Map(err)
 		}
-		res = append(res, &data)
+		callback(&data)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return m_errors.Map(err)
 	}
 
-	return res, nil
+	return nil
 }
 
 func (f *Facade) Find(
@@ -431,43 +509,41 @@ func (f *Facade) Find(
 	incomeID string,
 	fields []Field,
 ) (*Data, error) {
-	stringFields := makeStringFields(fields)
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1",
-		strings.Join(stringFields, ", "), Table, IncomeID)
-
-	var data Data
-	err := f.db.QueryRow(ctx, query, incomeID).Scan(data.fieldPtrs(fields)...)
-	if err != nil {
-		f.logError("Find", "Failed to QueryRow", logger.H{
-			"error":     err,
-			"income_id": incomeID,
-			"fields":    fields,
-		})
-		return nil, err
-	}
-
-	return &data, nil
+	return f.find(ctx, "Find", f.reader(ctx), incomeID, fields)
 }
 
 func (f *Facade) FindRtx(
 	ctx context.Context,
-	rtx pgx.Tx,
+	rtx m_options.Executor,
+	incomeID string,
+	fields []Field,
+) (*Data, error) {
+	return f.find(ctx, "FindRtx", rtx, incomeID, fields)
+}
+
+func (f *Facade) find(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
 	incomeID string,
 	fields []Field,
 ) (*Data, error) {
 	stringFields := makeStringFields(fields)
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1",
-		strings.Join(stringFields, ", "), Table, IncomeID)
+	query := f.andScope(fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1",
+		strings.Join(stringFields, ", "), Table, IncomeID))
 
 	var data Data
-	err := rtx.QueryRow(ctx, query, incomeID).Scan(data.fieldPtrs(fields)...)
+	err := data.scan(ex.QueryRow(ctx, query, incomeID), fields)
 	if err != nil {
-		f.logError("FindRtx", "Failed to QueryRow", logger.H{
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, m_errors.Map(err)
+		}
+		f.logError(functionName, "Failed to QueryRow", logger.H{
 			"error":     err,
 			"income_id": incomeID,
 			"fields":    fields,
 		})
-		return nil, err
+		return nil, m_errors.Map(err)
 	}
 
 	return &data, nil
@@ -482,7 +558,7 @@ func (f *Facade) Retrieve(
 
 func (f *Facade) RetrieveRtx(
 	ctx context.Context,
-	rtx pgx.Tx,
+	rtx m_options.Executor,
 	incomeID string,
 ) (*Data, error) {
 	return f.FindRtx(ctx, rtx, incomeID, allFieldsList)
@@ -490,47 +566,55 @@ func (f *Facade) RetrieveRtx(
 
 func (f *Facade) CreateTx(
 	ctx context.Context,
-	tx pgx.Tx,
+	tx m_options.Executor,
 	data *Data,
 ) error {
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6)",
-		Table, strings.Join(allStringFields, ", "))
+	if err := validateData(data); err != nil {
+		return err
+	}
+	f.generate(data)
+
+	query, values := insertQuery(data)
+	query += returning(allFieldsList)
 
-	_, err := tx.Exec(ctx, query, GetValues(data)...)
+	// Read back the stored row, including database defaults
+	err := m_audit.Run(ctx, tx, func(ctx context.Context, tx m_options.Executor) error {
+		return data.scan(tx.QueryRow(ctx, query, values...), allFieldsList)
+	})
 	if err != nil {
-		f.logError("CreateTx", "Failed to Exec", logger.H{
+		f.logError("CreateTx", "Failed to QueryRow", logger.H{
 			"error": err, "data": data,
 		})
-		return err
+		return m_errors.Map(err)
 	}
 	return nil
 }
 
+// UpdateTx sets data on the income with incomeID and returns the number of
+// rows affected, 0 when no income matched. With an expected version it fails
+// like UpdateVersionTx instead.
 func (f *Facade) UpdateTx(
 	ctx context.Context,
-	tx pgx.Tx,
+	tx m_options.Executor,
 	incomeID string,
-	data UpdateFields,
-) error {
+	update Updater,
+) (int64, error) {
+	data := fieldsOf(update)
 	if len(data) == 0 {
-		return nil
+		return 0, nil
 	}
-
-	setClauses := make([]string, 0, len(data))
-	args := make([]interface{}, 0, len(data)+1)
-	paramIdx := 1
-
-	for field, value := range data {
-		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", field, paramIdx))
-		args = append(args, value)
-		paramIdx++
+	if version := expectedVersion(data); version != 0 {
+		if err := f.UpdateVersionTx(ctx, tx, incomeID, version, data); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+	if err := validateUpdate(data); err != nil {
+		return 0, err
 	}
-	args = append(args, incomeID)
-
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d",
-		Table, strings.Join(setClauses, ", "), IncomeID, paramIdx)
 
-	_, err := tx.Exec(ctx, query, args...)
+	query, args := updateQuery(incomeID, data)
+	tag, err := f.exec(ctx, tx, f.andScope(query), args...)
 	if err != nil {
 		f.logError("UpdateTx", "Failed to Exec", logger.H{
 			"error": err, "primaryKeys": map[string]interface{}{
@@ -538,484 +622,277 @@ func (f *Facade) UpdateTx(
 			},
 			"data": data,
 		})
-		return err
+		return 0, m_errors.Map(err)
 	}
-	return nil
+	return tag.RowsAffected(), nil
+}
+
+// updateQuery builds the UPDATE of the income with incomeID.
+func updateQuery(incomeID string, data UpdateFields) (string, []interface{}) {
+	setClauses := make([]string, 0, len(data))
+	args := make([]interface{}, 0, len(data)+1)
+	paramIdx := 1
+
+	for _, field := range data.sortedFields() {
+		value := data[field]
+		if field == Version {
+			continue
+		}
+		var clause string
+		clause, args = sql_builder.SetClause(field.String(), value, args)
+		setClauses = append(setClauses, clause)
+		paramIdx = len(args) + 1
+	}
+	setClauses = append(setClauses, fmt.Sprintf("%s = %s + 1", Version, Version))
+	args = append(args, incomeID)
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d",
+		Table, strings.Join(setClauses, ", "), IncomeID, paramIdx)
+	return query, args
 }
 
 func (f *Facade) FindTx(
 	ctx context.Context,
-	tx pgx.Tx,
+	tx m_options.Executor,
 	incomeID string,
 	fields []Field,
 ) (*Data, error) {
-	stringFields := makeStringFields(fields)
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1",
-		strings.Join(stringFields, ", "), Table, IncomeID)
+	return f.find(ctx, "FindTx", tx, incomeID, fields)
+}
 
-	var data Data
-	err := tx.QueryRow(ctx, query, incomeID).Scan(data.fieldPtrs(fields)...)
-	if err != nil {
-		f.logError("FindTx", "Failed to QueryRow", logger.H{
-			"error":     err,
-			"income_id": incomeID,
-			"fields":    fields,
-		})
-		return nil, err
-	}
+func (c *Facade) GetByBuilder(ctx context.Context, builder *sql_builder.Builder[Field]) ([]*Data, error) {
+	return c.getByBuilder(ctx, "GetByBuilder", c.reader(ctx), builder)
+}
 
-	return &data, nil
+func (c *Facade) GetByBuilderRtx(ctx context.Context, rtx m_options.Executor, builder *sql_builder.Builder[Field]) ([]*Data, error) {
+	return c.getByBuilder(ctx, "GetByBuilderRtx", rtx, builder)
 }
 
-func (c *Facade) GetByBuilder(ctx context.Context, builder *sql_builder.Builder[Field]) ([]*Data, error) {
-	if builder == nil {
-		return nil, fmt.Errorf("builder cannot be nil")
-	}
-	queryStr := builder.String()
-	queryParams := builder.Params()
-	fields := builder.Fields()
+func (c *Facade) GetByBuilderTx(ctx context.Context, tx m_options.Executor, builder *sql_builder.Builder[Field]) ([]*Data, error) {
+	return c.getByBuilder(ctx, "GetByBuilderTx", tx, builder)
+}
 
-	rows, err := c.db.Query(ctx, queryStr, queryParams...)
+func (c *Facade) getByBuilder(ctx context.Context, functionName string, ex m_options.Executor, builder *sql_builder.Builder[Field]) ([]*Data, error) {
+	var res []*Data
+	err := c.getByBuilderIter(ctx, functionName, ex, builder, func(data *Data) {
+		res = append(res, data)
+	})
 	if err != nil {
-		c.logError("GetByBuilder", "Failed to Query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return nil, err
+		return nil, m_errors.Map(err)
 	}
-	defer rows.Close()
 
-	var res []*Data
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			c.logError("GetByBuilder", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return nil, err
-		}
-		res = append(res, &data)
-	}
+	return res, nil
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
+func (c *Facade) GetByBuilderIter(ctx context.Context, builder *sql_builder.Builder[Field], callback func(*Data)) error {
+	return c.getByBuilderIter(ctx, "GetByBuilderIter", c.reader(ctx), builder, callback)
+}
 
-	return res, nil
+func (c *Facade) GetByBuilderRtxIter(ctx context.Context, rtx m_options.Executor, builder *sql_builder.Builder[Field], callback func(*Data)) error {
+	return c.getByBuilderIter(ctx, "GetByBuilderRtxIter", rtx, builder, callback)
 }
 
-func (c *Facade) GetByBuilderRtx(ctx context.Context, rtx pgx.Tx, builder *sql_builder.Builder[Field]) ([]*Data, error) {
+func (c *Facade) GetByBuilderTxIter(ctx context.Context, tx m_options.Executor, builder *sql_builder.Builder[Field], callback func(*Data)) error {
+	return c.getByBuilderIter(ctx, "GetByBuilderTxIter", tx, builder, callback)
+}
+
+func (c *Facade) getByBuilderIter(ctx context.Context, functionName string, ex m_options.Executor, builder *sql_builder.Builder[Field], callback func(*Data)) error {
 	if builder == nil {
-		return nil, fmt.Errorf("builder cannot be nil")
+		return fmt.Errorf("builder cannot be nil")
 	}
-	queryStr := builder.String()
-	queryParams := builder.Params()
+	queryStr := builder.StringPostgresScoped(c.scope()...)
+	queryParams := builder.ArgsPostgres()
 	fields := builder.Fields()
+	if len(fields) == 0 {
+		fields = allFieldsList
+	}
 
-	rows, err := rtx.Query(ctx, queryStr, queryParams...)
+	rows, err := ex.Query(ctx, queryStr, queryParams...)
 	if err != nil {
-		c.logError("GetByBuilderRtx", "Failed to Query", logger.H{
+		c.logError(functionName, "Failed to Query", logger.H{
 			"error":  err,
 			"fields": fields,
 		})
-		return nil, err
+		return m_errors.Map(err)
 	}
 	defer rows.Close()
 
-	var res []*Data
 	for rows.Next() {
 		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			c.logError("GetByBuilderRtx", "Failed to Scan", logger.H{
+		if err := data.scan(rows, fields); err != nil {
+			c.logError(functionName, "Failed to Scan", logger.H{
 				"error":  err,
 				"fields": fields,
 			})
-			return nil, err
+			return m_errors.Map(err)
 		}
-		res = append(res, &data)
+		callback(&data)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return m_errors.Map(err)
 	}
 
-	return res, nil
+	return nil
 }
 
-func (c *Facade) GetByBuilderTx(ctx context.Context, tx pgx.Tx, builder *sql_builder.Builder[Field]) ([]*Data, error) {
-	if builder == nil {
-		return nil, fmt.Errorf("builder cannot be nil")
-	}
-	queryStr := builder.String()
-	queryParams := builder.Params()
-	fields := builder.Fields()
+func (f *Facade) GetTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	queryParams []QueryParam,
+	fields []Field,
+) ([]*Data, error) {
+	return f.get(ctx, "GetTx", tx, queryParams, fields)
+}
 
-	rows, err := tx.Query(ctx, queryStr, queryParams...)
+func (f *Facade) ExistTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	incomeID string,
+) (bool, error) {
+	query := fmt.Sprintf("SELECT EXISTS (%s)",
+		f.andScope(fmt.Sprintf("SELECT 1 FROM %s WHERE %s = $1", Table, ID)))
+	var exists bool
+	err := tx.QueryRow(ctx, query, incomeID).Scan(&exists)
 	if err != nil {
-		c.logError("GetByBuilderTx", "Failed to Query", logger.H{
-			"error":  err,
-			"fields": fields,
+		f.logError("ExistTx", "Failed to QueryRow", logger.H{
+			"error":     err,
+			"income_id": incomeID,
 		})
-		return nil, err
+		return false, m_errors.Map(err)
 	}
-	defer rows.Close()
+	return exists, nil
+}
 
-	var res []*Data
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			c.logError("GetByBuilderTx", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return nil, err
-		}
-		res = append(res, &data)
-	}
+func (c *Facade) InitBuilder() *sql_builder.Builder[Field] {
+	b := sql_builder.New[Field]("")
+	b.Select(allFieldsList...).From(Table)
+	return b
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
+// Update sets data on the income with incomeID and returns the number of
+// rows affected, 0 when no income matched.
+func (f *Facade) Update(
+	ctx context.Context,
+	incomeID string,
+	update Updater,
+) (int64, error) {
+	return f.UpdateTx(ctx, f.executor(ctx), incomeID, update)
+}
 
-	return res, nil
+// UpdateByParams sets data on every income matching queryParams and returns
+// the number of rows affected.
+func (f *Facade) UpdateByParams(
+	ctx context.Context,
+	queryParams []QueryParam,
+	update Updater,
+) (int64, error) {
+	return f.UpdateByParamsTx(ctx, f.executor(ctx), queryParams, update)
 }
 
-func (c *Facade) GetByBuilderIter(ctx context.Context, builder *sql_builder.Builder[Field], callback func(*Data)) error {
-	if builder == nil {
-		return fmt.Errorf("builder cannot be nil")
+func (f *Facade) UpdateByParamsTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	queryParams []QueryParam,
+	update Updater,
+) (int64, error) {
+	data := fieldsOf(update)
+	if len(data) == 0 {
+		return 0, nil
+	}
+	if err := validateUpdate(data); err != nil {
+		return 0, err
 	}
-	queryStr := builder.String()
-	queryParams := builder.Params()
-	fields := builder.Fields()
 
-	rows, err := c.db.Query(ctx, queryStr, queryParams...)
+	query, args := updateByParamsQuery(f.scoped(queryParams), data)
+	tag, err := f.exec(ctx, tx, query, args...)
 	if err != nil {
-		c.logError("GetByBuilderIter", "Failed to Query", logger.H{
-			"error":  err,
-			"fields": fields,
+		f.logError("UpdateByParams", "Failed to Exec", logger.H{
+			"error":        err,
+			"query_params": queryParams,
+			"data":         data,
 		})
-		return err
+		return 0, m_errors.Map(err)
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			c.logError("GetByBuilderIter", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return err
-		}
-		callback(&data)
-	}
-
-	if err := rows.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (c *Facade) GetByBuilderRtxIter(ctx context.Context, rtx pgx.Tx, builder *sql_builder.Builder[Field], callback func(*Data)) error {
-	if builder == nil {
-		return fmt.Errorf("builder cannot be nil")
-	}
-	queryStr := builder.String()
-	queryParams := builder.Params()
-	fields := builder.Fields()
-
-	rows, err := rtx.Query(ctx, queryStr, queryParams...)
-	if err != nil {
-		c.logError("GetByBuilderRtxIter", "Failed to Query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			c.logError("GetByBuilderRtxIter", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return err
-		}
-		callback(&data)
-	}
-
-	if err := rows.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (c *Facade) GetByBuilderTxIter(ctx context.Context, tx pgx.Tx, builder *sql_builder.Builder[Field], callback func(*Data)) error {
-	if builder == nil {
-		return fmt.Errorf("builder cannot be nil")
-	}
-	queryStr := builder.String()
-	queryParams := builder.Params()
-	fields := builder.Fields()
-
-	rows, err := tx.Query(ctx, queryStr, queryParams...)
-	if err != nil {
-		c.logError("GetByBuilderTxIter", "Failed to Query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			c.logError("GetByBuilderTxIter", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return err
-		}
-		callback(&data)
-	}
-
-	if err := rows.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
 
-func (f *Facade) GetTx(
-	ctx context.Context,
-	tx pgx.Tx,
-	queryParams []QueryParam,
-	fields []Field,
-) ([]*Data, error) {
-	q := SelectQuery(fields)
-	whereClause, params := ConstructWhereClause(queryParams)
-	if len(queryParams) > 0 {
-		q += " WHERE " + whereClause
-	}
-
-	// Convert map params to ordered slice
-	args := make([]interface{}, len(params))
-	for i := 0; i < len(params); i++ {
-		paramName := fmt.Sprintf("param%d", i)
-		args[i] = params[paramName]
-	}
-
-	rows, err := tx.Query(ctx, q, args...)
-	if err != nil {
-		f.logError("GetTx", "Failed to Query", logger.H{
-			"error":       err,
-			"queryParams": queryParams,
-			"fields":      fields,
-		})
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []*Data
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetTx", "Failed to Scan", logger.H{
-				"error":       err,
-				"queryParams": queryParams,
-				"fields":      fields,
-			})
-			return nil, err
-		}
-		res = append(res, &data)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return res, nil
-}
-
-func (f *Facade) ExistTx(
-	ctx context.Context,
-	tx pgx.Tx,
-	incomeID string,
-) bool {
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1", ID, Table, ID)
-	var id string
-	err := tx.QueryRow(ctx, query, incomeID).Scan(&id)
-	return err == nil
-}
-
-func (c *Facade) InitBuilder() *sql_builder.Builder[Field] {
-	b := sql_builder.New[Field]("")
-	b.Select(allFieldsList...).From(Table)
-	return b
+	return tag.RowsAffected(), nil
 }
 
-func (f *Facade) Update(
-	ctx context.Context,
-	incomeID string,
-	data UpdateFields,
-) error {
-	if len(data) == 0 {
-		return nil
-	}
-
-	setClauses := make([]string, 0, len(data))
-	args := make([]interface{}, 0, len(data)+1)
-	paramIdx := 1
-
-	for field, value := range data {
-		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", field, paramIdx))
-		args = append(args, value)
-		paramIdx++
-	}
-	args = append(args, incomeID)
-
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d",
-		Table, strings.Join(setClauses, ", "), IncomeID, paramIdx)
-
-	_, err := f.db.Exec(ctx, query, args...)
-	if err != nil {
-		f.logError("Update", "Failed to Exec", logger.H{
-			"error": err,
-			"data":  data,
+// updateByParamsQuery builds the UPDATE of every income matching queryParams.
+func updateByParamsQuery(queryParams []QueryParam, data UpdateFields) (string, []interface{}) {
+	if version := expectedVersion(data); version != 0 {
+		queryParams = append(queryParams[:len(queryParams):len(queryParams)], QueryParam{
+			Field: Version, Operator: OpEq, Value: version,
 		})
-		return fmt.Errorf("failed to update file record: %w", err)
 	}
 
-	return nil
-}
-
-func (f *Facade) UpdateByParams(
-	ctx context.Context,
-	queryParams []QueryParam,
-	data UpdateFields,
-) error {
-	if len(data) == 0 {
-		return nil
-	}
-
-	// Construct WHERE clause
-	whereClauses, whereParams := ConstructWhereClause(queryParams)
-
 	// Build SET clause
 	setClauses := make([]string, 0, len(data))
-	args := make([]interface{}, 0, len(data)+len(whereParams))
-	paramIdx := 1
+	args := make([]interface{}, 0, len(data)+len(queryParams))
 
 	// Add SET parameters
-	for field, value := range data {
-		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", field, paramIdx))
-		args = append(args, value)
-		paramIdx++
+	for _, field := range data.sortedFields() {
+		value := data[field]
+		if field == Version {
+			continue
+		}
+		var clause string
+		clause, args = sql_builder.SetClause(field.String(), value, args)
+		setClauses = append(setClauses, clause)
 	}
+	setClauses = append(setClauses, fmt.Sprintf("%s = %s + 1", Version, Version))
 
-	// Add WHERE parameters
+	// Construct WHERE clause, numbered after the SET parameters
+	whereClauses, whereParams := constructWhereClause(queryParams, len(args)+1)
 	for i := 0; i < len(whereParams); i++ {
 		paramName := fmt.Sprintf("param%d", i)
 		args = append(args, whereParams[paramName])
 	}
 
-	// Adjust WHERE clause to use correct parameter numbers
-	adjustedWhere := whereClauses
-	for i := len(data); i > 0; i-- {
-		adjustedWhere = strings.ReplaceAll(adjustedWhere,
-			fmt.Sprintf("$%d", i),
-			fmt.Sprintf("$%d", i+len(data)))
-	}
-
 	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
-		Table, strings.Join(setClauses, ", "), adjustedWhere)
-
-	_, err := f.db.Exec(ctx, query, args...)
-	if err != nil {
-		f.logError("UpdateByParams", "Failed to Exec", logger.H{
-			"error":        err,
-			"query_params": queryParams,
-			"data":         data,
-		})
-		return fmt.Errorf("failed to update file record: %w", err)
-	}
-
-	return nil
+		Table, strings.Join(setClauses, ", "), whereClauses)
+	return query, args
 }
 
+// Delete removes the income with incomeID and returns the number of rows
+// affected, 0 when no income matched.
 func (f *Facade) Delete(
 	ctx context.Context,
 	incomeID string,
-) error {
+) (int64, error) {
+	return f.DeleteTx(ctx, f.executor(ctx), incomeID)
+}
+
+func (f *Facade) DeleteTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	incomeID string,
+) (int64, error) {
 	query := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", Table, IncomeID)
+	args := []interface{}{incomeID}
+	if f.softDelete {
+		query, args = f.softDeleteQuery(incomeID)
+	}
 
-	_, err := f.db.Exec(ctx, query, incomeID)
+	tag, err := f.exec(ctx, tx, query, args...)
 	if err != nil {
 		f.logError("Delete", "Failed to Exec", logger.H{
 			"error": err,
 		})
-		return fmt.Errorf("failed to delete file record: %w", err)
+		return 0, m_errors.Map(err)
 	}
 
-	return nil
+	return tag.RowsAffected(), nil
 }
 
 func (f *Facade) GetRtxIter(
 	ctx context.Context,
-	rtx pgx.Tx,
+	rtx m_options.Executor,
 	queryParams []QueryParam,
 	fields []Field,
 	callback func(*Data),
 ) error {
-	// Construct SQL query
-	queryString := SelectQuery(fields)
-	whereClauses, params := ConstructWhereClause(queryParams)
-	if len(queryParams) > 0 {
-		queryString += " WHERE " + whereClauses
-	}
-
-	// Convert map params to ordered slice
-	args := make([]interface{}, len(params))
-	for i := 0; i < len(params); i++ {
-		paramName := fmt.Sprintf("param%d", i)
-		args[i] = params[paramName]
-	}
-
-	rows, err := rtx.Query(ctx, queryString, args...)
-	if err != nil {
-		f.logError("GetRtxIter", "Failed to Query", logger.H{
-			"error":        err,
-			"query_params": queryParams,
-			"fields":       fields,
-		})
-		return err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetRtxIter", "Failed to Scan", logger.H{
-				"error":        err,
-				"query_params": queryParams,
-				"fields":       fields,
-			})
-			return err
-		}
-		callback(&data)
-	}
-
-	if err := rows.Err(); err != nil {
-		return err
-	}
-
-	return nil
+	return f.getIter(ctx, "GetRtxIter", rtx, queryParams, fields, callback)
 }
 
 func (f *Facade) GetIter(
@@ -1024,58 +901,45 @@ func (f *Facade) GetIter(
 	fields []Field,
 	callback func(*Data),
 ) error {
-	// Construct SQL query
-	queryString := SelectQuery(fields)
-	whereClauses, params := ConstructWhereClause(queryParams)
-	if len(queryParams) > 0 {
-		queryString += " WHERE " + whereClauses
-	}
+	return f.getIter(ctx, "GetIter", f.reader(ctx), queryParams, fields, callback)
+}
 
-	// Convert map params to ordered slice
-	args := make([]interface{}, len(params))
-	for i := 0; i < len(params); i++ {
-		paramName := fmt.Sprintf("param%d", i)
-		args[i] = params[paramName]
-	}
+func (f *Facade) GetByPrimaryKeys(
+	ctx context.Context,
+	primaryKeys []PrimaryKey,
+	fields []Field,
+) ([]*Data, error) {
+	return f.getByPrimaryKeys(ctx, "GetByPrimaryKeys", f.reader(ctx), primaryKeys, fields)
+}
 
-	rows, err := f.db.Query(ctx, queryString, args...)
+func (f *Facade) getByPrimaryKeys(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	primaryKeys []PrimaryKey,
+	fields []Field,
+) ([]*Data, error) {
+	res := []*Data{}
+	err := f.getByPrimaryKeysIter(ctx, functionName, ex, primaryKeys, fields, func(data *Data) {
+		res = append(res, data)
+	})
 	if err != nil {
-		f.logError("GetIter", "Failed to Query", logger.H{
-			"error":        err,
-			"query_params": queryParams,
-			"fields":       fields,
-		})
-		return err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetIter", "Failed to Scan", logger.H{
-				"error":        err,
-				"query_params": queryParams,
-				"fields":       fields,
-			})
-			return err
-		}
-		callback(&data)
+		return nil, m_errors.Map(err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return err
-	}
-
-	return nil
+	return res, nil
 }
 
-func (f *Facade) GetByPrimaryKeys(
+func (f *Facade) getByPrimaryKeysIter(
 	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
 	primaryKeys []PrimaryKey,
 	fields []Field,
-) ([]*Data, error) {
+	callback func(*Data),
+) error {
 	if len(primaryKeys) == 0 {
-		return []*Data{}, nil
+		return nil
 	}
 
 	stringFields := makeStringFields(fields)
@@ -1084,37 +948,36 @@ func (f *Facade) GetByPrimaryKeys(
 		incomeIDs[i] = pk.IncomeID
 	}
 
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ANY($1)",
-		strings.Join(stringFields, ", "), Table, IncomeID)
+	query := f.andScope(fmt.Sprintf("SELECT %s FROM %s WHERE %s = ANY($1)",
+		strings.Join(stringFields, ", "), Table, IncomeID))
 
-	rows, err := f.db.Query(ctx, query, incomeIDs)
+	rows, err := ex.Query(ctx, query, incomeIDs)
 	if err != nil {
-		f.logError("GetByPrimaryKeys", "Failed to Query", logger.H{
+		f.logError(functionName, "Failed to Query", logger.H{
 			"error":  err,
 			"fields": fields,
 		})
-		return nil, err
+		return m_errors.Map(err)
 	}
 	defer rows.Close()
 
-	var res []*Data
 	for rows.Next() {
 		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByPrimaryKeys", "Failed to Scan", logger.H{
+		if err := data.scan(rows, fields); err != nil {
+			f.logError(functionName, "Failed to Scan", logger.H{
 				"error":  err,
 				"fields": fields,
 			})
-			return nil, err
+			return m_errors.Map(err)
 		}
-		res = append(res, &data)
+		callback(&data)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return m_errors.Map(err)
 	}
 
-	return res, nil
+	return nil
 }
 
 func (f *Facade) ListByPrimaryKeys(
@@ -1126,105 +989,25 @@ func (f *Facade) ListByPrimaryKeys(
 
 func (f *Facade) GetByPrimaryKeysRtx(
 	ctx context.Context,
-	rtx pgx.Tx,
+	rtx m_options.Executor,
 	primaryKeys []PrimaryKey,
 	fields []Field,
 ) ([]*Data, error) {
-	if len(primaryKeys) == 0 {
-		return []*Data{}, nil
-	}
-
-	stringFields := makeStringFields(fields)
-	incomeIDs := make([]string, len(primaryKeys))
-	for i, pk := range primaryKeys {
-		incomeIDs[i] = pk.IncomeID
-	}
-
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ANY($1)",
-		strings.Join(stringFields, ", "), Table, IncomeID)
-
-	rows, err := rtx.Query(ctx, query, incomeIDs)
-	if err != nil {
-		f.logError("GetByPrimaryKeysRtx", "Failed to Query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []*Data
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByPrimaryKeysRtx", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return nil, err
-		}
-		res = append(res, &data)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return res, nil
+	return f.getByPrimaryKeys(ctx, "GetByPrimaryKeysRtx", rtx, primaryKeys, fields)
 }
 
 func (f *Facade) GetByPrimaryKeysTx(
 	ctx context.Context,
-	tx pgx.Tx,
+	tx m_options.Executor,
 	primaryKeys []PrimaryKey,
 	fields []Field,
 ) ([]*Data, error) {
-	if len(primaryKeys) == 0 {
-		return []*Data{}, nil
-	}
-
-	stringFields := makeStringFields(fields)
-	incomeIDs := make([]string, len(primaryKeys))
-	for i, pk := range primaryKeys {
-		incomeIDs[i] = pk.IncomeID
-	}
-
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ANY($1)",
-		strings.Join(stringFields, ", "), Table, IncomeID)
-
-	rows, err := tx.Query(ctx, query, incomeIDs)
-	if err != nil {
-		f.logError("GetByPrimaryKeysTx", "Failed to Query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []*Data
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByPrimaryKeysTx", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return nil, err
-		}
-		res = append(res, &data)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return res, nil
+	return f.getByPrimaryKeys(ctx, "GetByPrimaryKeysTx", tx, primaryKeys, fields)
 }
 
 func (f *Facade) ListByPrimaryKeysRtx(
 	ctx context.Context,
-	rtx pgx.Tx,
+	rtx m_options.Executor,
 	primaryKeys []PrimaryKey,
 ) ([]*Data, error) {
 	return f.GetByPrimaryKeysRtx(ctx, rtx, primaryKeys, allFieldsList)
@@ -1232,7 +1015,7 @@ func (f *Facade) ListByPrimaryKeysRtx(
 
 func (f *Facade) ListByPrimaryKeysTx(
 	ctx context.Context,
-	tx pgx.Tx,
+	tx m_options.Executor,
 	primaryKeys []PrimaryKey,
 ) ([]*Data, error) {
 	return f.GetByPrimaryKeysTx(ctx, tx, primaryKeys, allFieldsList)
@@ -1244,46 +1027,7 @@ func (f *Facade) GetByPrimaryKeysIter(
 	fields []Field,
 	callback func(*Data),
 ) error {
-	if len(primaryKeys) == 0 {
-		return nil
-	}
-
-	stringFields := makeStringFields(fields)
-	incomeIDs := make([]string, len(primaryKeys))
-	for i, pk := range primaryKeys {
-		incomeIDs[i] = pk.IncomeID
-	}
-
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ANY($1)",
-		strings.Join(stringFields, ", "), Table, IncomeID)
-
-	rows, err := f.db.Query(ctx, query, incomeIDs)
-	if err != nil {
-		f.logError("GetByPrimaryKeysIter", "Failed to Query", logger.H{
-			"error":  err,
-			"fields": fields,
-		})
-		return err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var data Data
-		if err := rows.Scan(data.fieldPtrs(fields)...); err != nil {
-			f.logError("GetByPrimaryKeysIter", "Failed to Scan", logger.H{
-				"error":  err,
-				"fields": fields,
-			})
-			return err
-		}
-		callback(&data)
-	}
-
-	if err := rows.Err(); err != nil {
-		return err
-	}
-
-	return nil
+	return f.getByPrimaryKeysIter(ctx, "GetByPrimaryKeysIter", f.reader(ctx), primaryKeys, fields, callback)
 }
 
 func (f *Facade) ListByPrimaryKeysIter(
@@ -1303,7 +1047,7 @@ func (f *Facade) List(
 
 func (f *Facade) ListRtx(
 	ctx context.Context,
-	rtx pgx.Tx,
+	rtx m_options.Executor,
 	queryParams []QueryParam,
 ) ([]*Data, error) {
 	return f.GetRtx(ctx, rtx, queryParams, allFieldsList)
@@ -1319,7 +1063,7 @@ func (f *Facade) ListIter(
 
 func (f *Facade) ListRtxIter(
 	ctx context.Context,
-	rtx pgx.Tx,
+	rtx m_options.Executor,
 	queryParams []QueryParam,
 	callback func(*Data),
 ) error {
@@ -1346,26 +1090,30 @@ type OperationRead struct {
 	query     string
 	args      []interface{}
 	readtype  readtype
-	rtx       pgx.Tx
-	tx        pgx.Tx
+	ex        m_options.Executor
 	params    []interface{}
 	qp        []QueryParam
 	qb        *sql_builder.Builder[Field]
+	primary   bool
+}
+
+// executor returns the executor set with Rtx or Tx, falling back to the
+// context executor, and then to a replica or the primary pool.
+func (op *OperationRead) executor(ctx context.Context) m_options.Executor {
+	if op.ex != nil {
+		return op.ex
+	}
+	if op.primary {
+		return op.f.executor(ctx)
+	}
+	return op.f.reader(ctx)
 }
 
 func (op *OperationRead) Exists(
 	ctx context.Context,
 	incomeID string,
-) bool {
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1", ID, Table, ID)
-	var id string
-	var err error
-	if op.rtx != nil {
-		err = op.rtx.QueryRow(ctx, query, incomeID).Scan(&id)
-	} else {
-		err = op.f.db.QueryRow(ctx, query, incomeID).Scan(&id)
-	}
-	return err == nil
+) (bool, error) {
+	return op.f.ExistTx(ctx, op.executor(ctx), incomeID)
 }
 
 func (op *OperationRead) Columns(fields ...Field) *OperationRead {
@@ -1389,13 +1137,26 @@ func (op *OperationRead) Query(query string, args ...interface{}) *OperationRead
 	return op
 }
 
-func (op *OperationRead) Rtx(rtx pgx.Tx) *OperationRead {
-	op.rtx = rtx
+func (op *OperationRead) Rtx(rtx m_options.Executor) *OperationRead {
+	op.ex = rtx
 	return op
 }
 
-func (op *OperationRead) Tx(tx pgx.Tx) *OperationRead {
-	op.tx = tx
+func (op *OperationRead) Tx(tx m_options.Executor) *OperationRead {
+	op.ex = tx
+	return op
+}
+
+// Primary reads from the primary instead of a replica, e.g. to read your
+// own writes.
+func (op *OperationRead) Primary() *OperationRead {
+	op.primary = true
+	return op
+}
+
+// IncludeDeleted also returns soft-deleted incomes.
+func (op *OperationRead) IncludeDeleted() *OperationRead {
+	op.f = op.f.IncludeDeleted()
 	return op
 }
 
@@ -1467,24 +1228,18 @@ func (op *OperationRead) GetCount(ctx context.Context) (int64, error) {
 		op.SelectCount()
 	}
 
-	queryStr := op.qb.String()
-	queryParams := op.qb.Params()
+	queryStr := op.qb.StringPostgresScoped(op.f.scope()...)
+	queryParams := op.qb.ArgsPostgres()
 
 	var count int64
-	var err error
-	if op.rtx != nil {
-		err = op.rtx.QueryRow(ctx, queryStr, queryParams...).Scan(&count)
-	} else {
-		err = op.f.db.QueryRow(ctx, queryStr, queryParams...).Scan(&count)
-	}
-
+	err := op.executor(ctx).QueryRow(ctx, queryStr, queryParams...).Scan(&count)
 	if err != nil {
 		op.f.logError("GetCount", "Failed to Scan", logger.H{
 			"error": err,
 			"query": queryStr,
 			"param": queryParams,
 		})
-		return 0, err
+		return 0, m_errors.Map(err)
 	}
 
 	return count, nil
@@ -1495,34 +1250,24 @@ func (op *OperationRead) Rows(ctx context.Context) ([]*Data, error) {
 
 	var rows pgx.Rows
 	var err error
+	ex := op.executor(ctx)
 
 	switch op.readtype {
 	case byKeys:
-		query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ANY($1)",
-			strings.Join(op.strFields, ", "), Table, IncomeID)
-		if op.rtx != nil {
-			rows, err = op.rtx.Query(ctx, query, op.args...)
-		} else {
-			rows, err = op.f.db.Query(ctx, query, op.args...)
-		}
+		query := op.f.andScope(fmt.Sprintf("SELECT %s FROM %s WHERE %s = ANY($1)",
+			strings.Join(op.strFields, ", "), Table, IncomeID))
+		rows, err = ex.Query(ctx, query, op.args...)
 	case byQuery:
-		if op.rtx != nil {
-			rows, err = op.rtx.Query(ctx, op.query, op.args...)
-		} else {
-			rows, err = op.f.db.Query(ctx, op.query, op.args...)
-		}
+		rows, err = ex.Query(ctx, op.query, op.args...)
 	case byBuilder:
-		queryStr := op.qb.String()
-		queryParams := op.qb.Params()
-		if op.rtx != nil {
-			rows, err = op.rtx.Query(ctx, queryStr, queryParams...)
-		} else {
-			rows, err = op.f.db.Query(ctx, queryStr, queryParams...)
-		}
+		queryStr := op.qb.StringPostgresScoped(op.f.scope()...)
+		queryParams := op.qb.ArgsPostgres()
+		rows, err = ex.Query(ctx, queryStr, queryParams...)
 	case byParams:
+		qp := op.f.scoped(op.qp)
 		queryString := SelectQuery(op.fields)
-		whereClauses, params := ConstructWhereClause(op.qp)
-		if op.qp != nil && len(op.qp) > 0 {
+		whereClauses, params := ConstructWhereClause(qp)
+		if len(qp) > 0 {
 			queryString += " WHERE " + whereClauses
 		}
 		args := make([]interface{}, len(params))
@@ -1530,11 +1275,7 @@ func (op *OperationRead) Rows(ctx context.Context) ([]*Data, error) {
 			paramName := fmt.Sprintf("param%d", i)
 			args[i] = params[paramName]
 		}
-		if op.rtx != nil {
-			rows, err = op.rtx.Query(ctx, queryString, args...)
-		} else {
-			rows, err = op.f.db.Query(ctx, queryString, args...)
-		}
+		rows, err = ex.Query(ctx, queryString, args...)
 	case byCounter:
 		return nil, fmt.Errorf("OperationRead Rows: byCounter is not supported. Use GetCount instead")
 	default:
@@ -1547,26 +1288,26 @@ func (op *OperationRead) Rows(ctx context.Context) ([]*Data, error) {
 			"query_params": op.params,
 			"fields":       op.fields,
 		})
-		return nil, err
+		return nil, m_errors.Map(err)
 	}
 	defer rows.Close()
 
 	var res []*Data
 	for rows.Next() {
 		var data Data
-		if err := rows.Scan(data.fieldPtrs(op.fields)...); err != nil {
+		if err := data.scan(rows, op.fields); err != nil {
 			op.f.logError("OperationRead Rows", "Failed to Scan", logger.H{
 				"error":        err,
 				"query_params": op.params,
 				"fields":       op.fields,
 			})
-			return nil, err
+			return nil, m_errors.Map(err)
 		}
 		res = append(res, &data)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, m_errors.Map(err)
 	}
 
 	return res, nil
@@ -1575,7 +1316,7 @@ func (op *OperationRead) Rows(ctx context.Context) ([]*Data, error) {
 func (op *OperationRead) DoIter(ctx context.Context, callback func(*Data)) error {
 	rows, err := op.Rows(ctx)
 	if err != nil {
-		return err
+		return m_errors.Map(err)
 	}
 
 	for _, row := range rows {
@@ -1593,32 +1334,12 @@ func (op *OperationRead) SingleRow(
 		op.fields = allFieldsList
 	}
 
-	stringFields := convertColumns(op.fields)
-	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1",
-		strings.Join(stringFields, ", "), Table, IncomeID)
-
-	var data Data
-	var err error
-	if op.rtx != nil {
-		err = op.rtx.QueryRow(ctx, query, incomeID).Scan(data.fieldPtrs(op.fields)...)
-	} else {
-		err = op.f.db.QueryRow(ctx, query, incomeID).Scan(data.fieldPtrs(op.fields)...)
-	}
-
-	if err != nil {
-		op.f.logError("SingleRow", "Failed to QueryRow", logger.H{
-			"error":     err,
-			"fields":    op.fields,
-			"income_id": incomeID,
-		})
-		return nil, err
-	}
-
-	return &data, nil
+	return op.f.find(ctx, "SingleRow", op.executor(ctx), incomeID, op.fields)
 }
 
 type OperationWrite struct {
 	f       *Facade
+	tx      m_options.Executor
 	creates []*Data
 	updates []updateOp
 	deletes []string
@@ -1627,20 +1348,38 @@ type OperationWrite struct {
 
 type updateOp struct {
 	incomeID string
+	version  int64 // 0 applies the update at any version
 	data     UpdateFields
 }
 
 func (op *OperationWrite) Update(
 	incomeID string,
-	data UpdateFields,
+	update Updater,
 ) *OperationWrite {
+	data := fieldsOf(update)
 	op.updates = append(op.updates, updateOp{
 		incomeID: incomeID,
+		version:  expectedVersion(data),
 		data:     data,
 	})
 	return op
 }
 
+// UpdateVersion queues an update that fails the batch with
+// m_errors.ErrStaleVersion unless the income is at version.
+func (op *OperationWrite) UpdateVersion(
+	incomeID string,
+	version int64,
+	update Updater,
+) *OperationWrite {
+	op.updates = append(op.updates, updateOp{
+		incomeID: incomeID,
+		version:  version,
+		data:     fieldsOf(update),
+	})
+	return op
+}
+
 func (op *OperationWrite) Create(data *Data) *OperationWrite {
 	op.creates = append(op.creates, data)
 	return op
@@ -1651,111 +1390,108 @@ func (op *OperationWrite) Put(data *Data) *OperationWrite {
 	return op
 }
 
+// Tx makes Apply run inside tx. Apply then works in a savepoint, so a failing
+// batch is rolled back on its own and the outer transaction stays usable.
+func (op *OperationWrite) Tx(tx m_options.Executor) *OperationWrite {
+	op.tx = tx
+	return op
+}
+
+// validate checks every queued write before anything is sent, so an invalid
+// row fails the batch without a round trip.
+func (op *OperationWrite) validate() error {
+	for _, data := range op.creates {
+		if err := validateData(data); err != nil {
+			return err
+		}
+	}
+	for _, data := range op.puts {
+		if err := validateData(data); err != nil {
+			return err
+		}
+	}
+	for _, update := range op.updates {
+		if err := validateUpdate(update.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply runs every queued write atomically. Without an outer transaction it
+// begins one on the pool; inside a transaction (set with Tx, carried by the
+// context or bound with WithExecutor) it uses SAVEPOINT and RELEASE, and
+// ROLLBACK TO SAVEPOINT on failure.
 func (op *OperationWrite) Apply(ctx context.Context) error {
-	tx, err := op.f.db.Begin(ctx)
+	if err := op.validate(); err != nil {
+		return err
+	}
+
+	ex := op.tx
+	if ex == nil {
+		ex = op.f.executor(ctx)
+	}
+
+	tx, err := m_options.Begin(ctx, ex)
 	if err != nil {
 		op.f.logError("OperationWrite Apply", "Failed to Begin transaction", logger.H{
 			"error": err,
 		})
-		return err
+		return m_errors.Map(err)
 	}
 	defer tx.Rollback(ctx)
 
+	// Set the audit actor once for the whole batch
+	if err := m_audit.Run(ctx, tx, op.apply); err != nil {
+		return m_errors.Map(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		op.f.logError("OperationWrite Apply", "Failed to Commit transaction", logger.H{
+			"error": err,
+		})
+		return m_errors.Map(err)
+	}
+
+	return nil
+}
+
+// apply runs the queued writes on tx.
+func (op *OperationWrite) apply(ctx context.Context, tx m_options.Executor) error {
 	// Execute creates
 	for _, data := range op.creates {
-		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6)",
-			Table, strings.Join(allStringFields, ", "))
-		_, err := tx.Exec(ctx, query, GetValues(data)...)
-		if err != nil {
-			op.f.logError("OperationWrite Apply", "Failed to insert", logger.H{
-				"error": err,
-				"data":  data,
-			})
-			return err
+		if err := op.f.CreateTx(ctx, tx, data); err != nil {
+			return m_errors.Map(err)
 		}
 	}
 
 	// Execute puts (upserts)
 	for _, data := range op.puts {
-		query := fmt.Sprintf(`
-			INSERT INTO %s (%s)
-			VALUES ($1, $2, $3, $4, $5, $6)
-			ON CONFLICT (%s) DO UPDATE SET
-				%s = EXCLUDED.%s,
-				%s = EXCLUDED.%s,
-				%s = EXCLUDED.%s,
-				%s = EXCLUDED.%s,
-				%s = EXCLUDED.%s
-		`, Table,
-			strings.Join(allStringFields, ", "),
-			IncomeID,
-			IncomeName, IncomeName,
-			IncomeAmount, IncomeAmount,
-			IncomeType, IncomeType,
-			IncomeDate, IncomeDate,
-			CreatedAt, CreatedAt,
-		)
-		_, err := tx.Exec(ctx, query, GetValues(data)...)
-		if err != nil {
-			op.f.logError("OperationWrite Apply", "Failed to upsert", logger.H{
-				"error": err,
-				"data":  data,
-			})
-			return err
+		if err := op.f.CreateOrUpdateTx(ctx, tx, data); err != nil {
+			return m_errors.Map(err)
 		}
 	}
 
 	// Execute updates
 	for _, update := range op.updates {
-		if len(update.data) == 0 {
+		if update.version != 0 {
+			if err := op.f.UpdateVersionTx(ctx, tx, update.incomeID, update.version, update.data); err != nil {
+				return m_errors.Map(err)
+			}
 			continue
 		}
-
-		setClauses := make([]string, 0, len(update.data))
-		args := make([]interface{}, 0, len(update.data)+1)
-		paramIdx := 1
-
-		for field, value := range update.data {
-			setClauses = append(setClauses, fmt.Sprintf("%s = $%d", field, paramIdx))
-			args = append(args, value)
-			paramIdx++
-		}
-		args = append(args, update.incomeID)
-
-		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d",
-			Table, strings.Join(setClauses, ", "), IncomeID, paramIdx)
-
-		_, err := tx.Exec(ctx, query, args...)
-		if err != nil {
-			op.f.logError("OperationWrite Apply", "Failed to update", logger.H{
-				"error":     err,
-				"income_id": update.incomeID,
-				"data":      update.data,
-			})
-			return err
+		if _, err := op.f.UpdateTx(ctx, tx, update.incomeID, update.data); err != nil {
+			return m_errors.Map(err)
 		}
 	}
 
 	// Execute deletes
 	for _, incomeID := range op.deletes {
-		query := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", Table, IncomeID)
-		_, err := tx.Exec(ctx, query, incomeID)
-		if err != nil {
-			op.f.logError("OperationWrite Apply", "Failed to delete", logger.H{
-				"error":     err,
-				"income_id": incomeID,
-			})
-			return err
+		if _, err := op.f.DeleteTx(ctx, tx, incomeID); err != nil {
+			return m_errors.Map(err)
 		}
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		op.f.logError("OperationWrite Apply", "Failed to Commit transaction", logger.H{
-			"error": err,
-		})
-		return err
-	}
-
 	return nil
 }
 
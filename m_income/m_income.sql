@@ -1,9 +1,39 @@
-CREATE TABLE incomes (
-                     income_id VARCHAR(36) NOT NULL PRIMARY KEY,
-                     income_name VARCHAR(255),
-                     income_amount NUMERIC,
-                     -- ENUM(salary, transfer, others) COLUMN type
-                     income_type VARCHAR(50),
-                     income_date TIMESTAMP,
-                     created_at TIMESTAMP
-);
\ No newline at end of file
+-- PostgreSQL table definition for incomes
+-- Kept in sync with m_migrate/migrations; apply the schema with Model.Migrate or cmd/migrate.
+-- Create ENUM type for income types
+CREATE TYPE income_type_enum AS ENUM (
+    'salary',
+    'transfer',
+    'others'
+);
+
+-- Create incomes table
+CREATE TABLE incomes
+(
+    income_id     UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    income_name   VARCHAR(255),
+    income_amount DECIMAL(15, 2) CONSTRAINT incomes_income_amount_positive CHECK (income_amount > 0),
+    income_currency CHAR(3) NOT NULL DEFAULT 'UAH' CHECK (income_currency ~ '^[A-Z]{3}$'),
+    income_type   income_type_enum,
+    income_date   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at    TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at    TIMESTAMP WITH TIME ZONE,
+    version       BIGINT NOT NULL DEFAULT 1
+);
+
+-- Create indexes for better query performance
+CREATE INDEX idx_incomes_date ON incomes(income_date);
+CREATE INDEX idx_incomes_type ON incomes(income_type);
+CREATE INDEX idx_incomes_deleted_at ON incomes(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Add comments for documentation
+COMMENT ON TABLE incomes IS 'Table to store income records';
+COMMENT ON COLUMN incomes.income_id IS 'Unique identifier for income (UUID)';
+COMMENT ON COLUMN incomes.income_name IS 'Name/description of the income';
+COMMENT ON COLUMN incomes.income_amount IS 'Amount of the income';
+COMMENT ON COLUMN incomes.income_currency IS 'ISO 4217 currency of the income amount';
+COMMENT ON COLUMN incomes.income_type IS 'Category/type of income';
+COMMENT ON COLUMN incomes.income_date IS 'Date when income was received';
+COMMENT ON COLUMN incomes.created_at IS 'Record creation timestamp';
+COMMENT ON COLUMN incomes.deleted_at IS 'Soft delete timestamp, NULL while the record is live';
+COMMENT ON COLUMN incomes.version IS 'Row version, incremented on every write';
@@ -0,0 +1,140 @@
+package m_income
+
+import (
+	"context"
+	"time"
+
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/db-fd-model/m_types"
+)
+
+// Data read from the database remembers the values it was read with, so
+// Save can send only the columns changed since. Diff compares two Data
+// built by the caller.
+
+// rowScanner is implemented by pgx.Row and pgx.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scan reads fields from row into data and remembers them for Save.
+func (data *Data) scan(row rowScanner, fields []Field) error {
+	if err := row.Scan(data.fieldPtrs(fields)...); err != nil {
+		return err
+	}
+	original := data.clone()
+	data.original = &original
+	return nil
+}
+
+// clone copies data, including the values its pointers refer to, without
+// the remembered original.
+func (data *Data) clone() Data {
+	c := *data
+	c.original = nil
+	c.IncomeName = clonePtr(data.IncomeName)
+	c.IncomeAmount = clonePtr(data.IncomeAmount)
+	c.IncomeCurrency = clonePtr(data.IncomeCurrency)
+	c.IncomeType = clonePtr(data.IncomeType)
+	c.IncomeDate = clonePtr(data.IncomeDate)
+	c.CreatedAt = clonePtr(data.CreatedAt)
+	c.DeletedAt = clonePtr(data.DeletedAt)
+	return c
+}
+
+// Changes returns the columns changed since data was read. Columns the read
+// did not select count as changed once set. For Data that was not read from
+// the database it returns every column that is set.
+func (data *Data) Changes() UpdateFields {
+	if data.original == nil {
+		return Diff(&Data{}, data)
+	}
+	return Diff(data.original, data)
+}
+
+// Diff returns the columns whose value differs between old and new, set to
+// their value in new. The primary key and Version are never included.
+func Diff(old, new *Data) UpdateFields {
+	diff := make(UpdateFields)
+	if !same(old.IncomeName, new.IncomeName, eq[string]) {
+		diff[IncomeName] = new.IncomeName
+	}
+	if !same(old.IncomeAmount, new.IncomeAmount, m_types.Money.Equal) {
+		diff[IncomeAmount] = new.IncomeAmount
+	}
+	if !same(old.IncomeCurrency, new.IncomeCurrency, eq[m_types.Currency]) {
+		diff[IncomeCurrency] = new.IncomeCurrency
+	}
+	if !same(old.IncomeType, new.IncomeType, eq[string]) {
+		diff[IncomeType] = new.IncomeType
+	}
+	if !same(old.IncomeDate, new.IncomeDate, time.Time.Equal) {
+		diff[IncomeDate] = new.IncomeDate
+	}
+	if !same(old.CreatedAt, new.CreatedAt, time.Time.Equal) {
+		diff[CreatedAt] = new.CreatedAt
+	}
+	if !same(old.DeletedAt, new.DeletedAt, time.Time.Equal) {
+		diff[DeletedAt] = new.DeletedAt
+	}
+	return diff
+}
+
+// Save updates the columns of data changed since it was read, see Changes,
+// and refreshes data with the stored row. When data was read with its
+// Version, the update only applies at that version and otherwise fails with
+// m_errors.ErrStaleVersion. Save does nothing when no column changed, and
+// returns an error matching m_errors.ErrNotFound when the income is gone.
+func (f *Facade) Save(
+	ctx context.Context,
+	data *Data,
+) error {
+	return f.SaveTx(ctx, f.executor(ctx), data)
+}
+
+func (f *Facade) SaveTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	data *Data,
+) error {
+	diff := data.Changes()
+	if len(diff) == 0 {
+		return nil
+	}
+
+	var (
+		saved *Data
+		err   error
+	)
+	if data.original != nil && data.original.Version != 0 {
+		saved, err = f.UpdateVersionReturningTx(ctx, tx, data.IncomeID, data.original.Version, diff, nil)
+	} else {
+		saved, err = f.UpdateReturningTx(ctx, tx, data.IncomeID, diff, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	*data = *saved
+	return nil
+}
+
+// same reports whether a and b are both NULL or hold equal values.
+func same[T any](a, b *T, equal func(T, T) bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return equal(*a, *b)
+}
+
+func eq[T comparable](a, b T) bool {
+	return a == b
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
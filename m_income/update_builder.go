@@ -0,0 +1,145 @@
+package m_income
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/rsmrtk/db-fd-model/m_types"
+	"github.com/rsmrtk/db-fd-model/sql_builder"
+)
+
+// Updater is the set of columns an update writes. It is implemented by
+// UpdateFields and by the typed *UpdateBuilder.
+type Updater interface {
+	Fields() UpdateFields
+}
+
+// Fields returns uf itself.
+func (uf UpdateFields) Fields() UpdateFields {
+	return uf
+}
+
+// sortedFields returns the fields of uf in name order, so the same update
+// always renders the same SQL.
+func (uf UpdateFields) sortedFields() []Field {
+	return slices.Sorted(maps.Keys(uf))
+}
+
+// fieldsOf returns the columns set by update; nil sets none.
+func fieldsOf(update Updater) UpdateFields {
+	if update == nil {
+		return nil
+	}
+	return update.Fields()
+}
+
+// UpdateBuilder sets columns through typed setters, so a value of the wrong
+// type fails to compile instead of failing at the database. Only columns
+// that are nullable and not Required by Validator have a Null setter; the
+// primary key and Version cannot be set, but ExpectVersion makes the update
+// conditional on Version.
+// Incr, Decr, Default and Now setters, and SetExpr, update from the current
+// row in the database without reading it first.
+type UpdateBuilder struct {
+	fields UpdateFields
+}
+
+// NewUpdate returns an empty update.
+func NewUpdate() *UpdateBuilder {
+	return &UpdateBuilder{fields: make(UpdateFields)}
+}
+
+// Fields returns a copy of the columns set so far.
+func (b *UpdateBuilder) Fields() UpdateFields {
+	if b == nil {
+		return nil
+	}
+	return maps.Clone(b.fields)
+}
+
+func (b *UpdateBuilder) set(field Field, value interface{}) *UpdateBuilder {
+	if b.fields == nil {
+		b.fields = make(UpdateFields)
+	}
+	b.fields[field] = value
+	return b
+}
+
+// ExpectVersion makes the update fail with m_errors.ErrStaleVersion unless
+// the income is still at version, like UpdateVersion.
+func (b *UpdateBuilder) ExpectVersion(version int64) *UpdateBuilder {
+	return b.set(Version, version)
+}
+
+// SetExpr sets field to an expression, e.g. sql_builder.Coalesce(v). An
+// expression on the primary key, Version, CreatedAt or DeletedAt fails the
+// update with m_errors.ErrValidation.
+func (b *UpdateBuilder) SetExpr(field Field, expr sql_builder.Expr) *UpdateBuilder {
+	return b.set(field, expr)
+}
+
+func (b *UpdateBuilder) SetIncomeName(v string) *UpdateBuilder {
+	return b.set(IncomeName, &v)
+}
+
+func (b *UpdateBuilder) SetIncomeAmount(v m_types.Money) *UpdateBuilder {
+	return b.set(IncomeAmount, &v)
+}
+
+func (b *UpdateBuilder) IncrIncomeAmount(v m_types.Money) *UpdateBuilder {
+	return b.set(IncomeAmount, sql_builder.Incr(v))
+}
+
+func (b *UpdateBuilder) DecrIncomeAmount(v m_types.Money) *UpdateBuilder {
+	return b.set(IncomeAmount, sql_builder.Decr(v))
+}
+
+func (b *UpdateBuilder) SetIncomeCurrency(v m_types.Currency) *UpdateBuilder {
+	return b.set(IncomeCurrency, &v)
+}
+
+func (b *UpdateBuilder) SetIncomeCurrencyDefault() *UpdateBuilder {
+	return b.set(IncomeCurrency, sql_builder.Default())
+}
+
+func (b *UpdateBuilder) SetIncomeType(v EnumType) *UpdateBuilder {
+	s := string(v)
+	return b.set(IncomeType, &s)
+}
+
+func (b *UpdateBuilder) SetIncomeTypeNull() *UpdateBuilder {
+	return b.set(IncomeType, (*string)(nil))
+}
+
+func (b *UpdateBuilder) SetIncomeDate(v time.Time) *UpdateBuilder {
+	return b.set(IncomeDate, &v)
+}
+
+func (b *UpdateBuilder) SetIncomeDateNull() *UpdateBuilder {
+	return b.set(IncomeDate, (*time.Time)(nil))
+}
+
+func (b *UpdateBuilder) SetIncomeDateDefault() *UpdateBuilder {
+	return b.set(IncomeDate, sql_builder.Default())
+}
+
+func (b *UpdateBuilder) SetIncomeDateNow() *UpdateBuilder {
+	return b.set(IncomeDate, sql_builder.Now())
+}
+
+func (b *UpdateBuilder) SetCreatedAt(v time.Time) *UpdateBuilder {
+	return b.set(CreatedAt, &v)
+}
+
+func (b *UpdateBuilder) SetCreatedAtNull() *UpdateBuilder {
+	return b.set(CreatedAt, (*time.Time)(nil))
+}
+
+func (b *UpdateBuilder) SetDeletedAt(v time.Time) *UpdateBuilder {
+	return b.set(DeletedAt, &v)
+}
+
+func (b *UpdateBuilder) SetDeletedAtNull() *UpdateBuilder {
+	return b.set(DeletedAt, (*time.Time)(nil))
+}
@@ -0,0 +1,51 @@
+package m_income
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/sql_builder"
+)
+
+// Every Null setter must produce an update that passes Validator, otherwise
+// it could never succeed.
+func TestNullSettersPassValidation(t *testing.T) {
+	builder := reflect.TypeOf(NewUpdate())
+	for i := 0; i < builder.NumMethod(); i++ {
+		method := builder.Method(i)
+		if !strings.HasPrefix(method.Name, "Set") || !strings.HasSuffix(method.Name, "Null") {
+			continue
+		}
+		out := method.Func.Call([]reflect.Value{reflect.ValueOf(NewUpdate())})
+		update := out[0].Interface().(*UpdateBuilder)
+		if err := validateUpdate(update.Fields()); err != nil {
+			t.Errorf("%s: %v", method.Name, err)
+		}
+	}
+}
+
+func TestSetExprRejectsManagedColumns(t *testing.T) {
+	for _, field := range []Field{IncomeID, Version, CreatedAt, DeletedAt} {
+		update := NewUpdate().SetExpr(field, sql_builder.Now())
+		if err := validateUpdate(update.Fields()); !errors.Is(err, m_errors.ErrValidation) {
+			t.Errorf("%s: got %v, want ErrValidation", field, err)
+		}
+	}
+}
+
+func TestExpectVersion(t *testing.T) {
+	update := NewUpdate().SetIncomeName("rent").ExpectVersion(7)
+
+	op := (&OperationWrite{}).Update("id", update)
+	if op.updates[0].version != 7 {
+		t.Errorf("OperationWrite.Update expects version %d, want 7", op.updates[0].version)
+	}
+
+	query, args := updateByParamsQuery([]QueryParam{{Field: IncomeName, Operator: OpEq, Value: "old"}}, update.Fields())
+	if !strings.Contains(query, "version = $3") || args[len(args)-1] != int64(7) {
+		t.Errorf("UpdateByParams does not expect version 7: %s %v", query, args)
+	}
+}
@@ -0,0 +1,43 @@
+package m_income
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/rsmrtk/db-fd-model/m_types"
+	"github.com/rsmrtk/db-fd-model/sql_builder"
+)
+
+func TestUpdateByParamsQueryNumbersPlaceholders(t *testing.T) {
+	name := "salary"
+	amount := m_types.MustParseDecimal("5")
+	data := UpdateFields{
+		IncomeName:   sql_builder.Coalesce(&name),
+		IncomeAmount: &amount,
+	}
+
+	var queryParams []QueryParam
+	for i := 0; i < 12; i++ {
+		queryParams = append(queryParams, QueryParam{Field: IncomeName, Operator: OpNe, Value: strconv.Itoa(i)})
+	}
+	queryParams = append(queryParams, QueryParam{Field: DeletedAt, Operator: OpIs, Value: nil})
+
+	query, args := updateByParamsQuery(queryParams, data)
+	if len(args) != 14 {
+		t.Fatalf("got %d args, want 14", len(args))
+	}
+
+	placeholders := regexp.MustCompile(`\$(\d+)`).FindAllStringSubmatch(query, -1)
+	if len(placeholders) != len(args) {
+		t.Fatalf("got %d placeholders for %d args: %s", len(placeholders), len(args), query)
+	}
+	for i, p := range placeholders {
+		if p[1] != strconv.Itoa(i+1) {
+			t.Fatalf("placeholder %d is $%s, want $%d: %s", i, p[1], i+1, query)
+		}
+	}
+	if args[13] != "11" {
+		t.Errorf("last WHERE arg is %v, want 11", args[13])
+	}
+}
@@ -0,0 +1,195 @@
+package m_income
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rsmrtk/db-fd-model/m_audit"
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// Upsert configures the INSERT ... ON CONFLICT of Upsert and UpsertTx. The
+// zero value conflicts on the primary key and overwrites every column except
+// created_at and deleted_at, which is also what CreateOrUpdate and
+// OperationWrite.Put do.
+type Upsert struct {
+	// Conflict target: the columns of a unique index, or the name of a
+	// unique or exclusion constraint. Both empty means the primary key.
+	Conflict   []Field
+	Constraint string
+
+	// Columns overwritten on conflict; nil means every column except the
+	// primary key, the Conflict columns, created_at and deleted_at, so an
+	// upsert neither rewrites the creation time nor restores a soft deleted
+	// row. List CreatedAt or DeletedAt here to overwrite them. Preserve
+	// removes columns from the list. Version is always incremented.
+	Update   []Field
+	Preserve []Field
+
+	// DoNothing leaves the existing row untouched.
+	DoNothing bool
+
+	// Where limits the update to existing rows matching every param; other
+	// conflicting rows are left untouched.
+	Where []QueryParam
+}
+
+// UpsertResult reports what an upsert did with the row.
+type UpsertResult string
+
+const (
+	UpsertInserted UpsertResult = "inserted"
+	UpsertUpdated  UpsertResult = "updated"
+	UpsertSkipped  UpsertResult = "skipped" // DoNothing, or Where did not match
+)
+
+// updateFields returns the columns set on conflict, in column order.
+func (u Upsert) updateFields() []Field {
+	fields := u.Update
+	if fields == nil {
+		fields = slices.DeleteFunc(slices.Clone(allFieldsList), func(field Field) bool {
+			return field == CreatedAt || field == DeletedAt || slices.Contains(u.Conflict, field)
+		})
+	}
+	return slices.DeleteFunc(slices.Clone(fields), func(field Field) bool {
+		return field == IncomeID || field == Version || slices.Contains(u.Preserve, field)
+	})
+}
+
+// upsertQuery inserts every column of GetValues and resolves conflicts as
+// configured by u. The returned arguments of the Where clause follow the
+// inserted values.
+func upsertQuery(u Upsert) (string, []interface{}, error) {
+	var target string
+	switch {
+	case u.Constraint != "" && len(u.Conflict) > 0:
+		return "", nil, fmt.Errorf("%s: upsert takes Conflict or Constraint, not both", Package)
+	case u.Constraint != "":
+		target = "ON CONSTRAINT " + pgx.Identifier{u.Constraint}.Sanitize()
+	case len(u.Conflict) > 0:
+		target = "(" + strings.Join(makeStringFields(u.Conflict), ", ") + ")"
+	default:
+		target = "(" + IncomeID.String() + ")"
+	}
+
+	placeholders := make([]string, len(allFieldsList))
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT %s",
+		Table, strings.Join(allStringFields, ", "), strings.Join(placeholders, ", "), target)
+
+	if u.DoNothing {
+		return query + " DO NOTHING", nil, nil
+	}
+
+	updateFields := u.updateFields()
+	setClauses := make([]string, 0, len(updateFields)+1)
+	for _, field := range updateFields {
+		setClauses = append(setClauses, fmt.Sprintf("%s = EXCLUDED.%s", field, field))
+	}
+	setClauses = append(setClauses, fmt.Sprintf("%s = %s.%s + 1", Version, Table, Version))
+	query += " DO UPDATE SET " + strings.Join(setClauses, ", ")
+
+	if len(u.Where) == 0 {
+		return query, nil, nil
+	}
+	where, args := conflictWhere(u.Where, len(allFieldsList)+1)
+	return query + " WHERE " + where, args, nil
+}
+
+// conflictWhere renders the WHERE of DO UPDATE, numbering placeholders from
+// paramIdx. Columns are qualified with the table name, as EXCLUDED is in
+// scope as well.
+func conflictWhere(queryParams []QueryParam, paramIdx int) (string, []interface{}) {
+	clauses := make([]string, len(queryParams))
+	args := make([]interface{}, 0, len(queryParams))
+	for i, qp := range queryParams {
+		column := fmt.Sprintf("%s.%s", Table, qp.Field)
+		switch {
+		case (qp.Operator == OpIs || qp.Operator == OpIsNot) && qp.Value == nil:
+			clauses[i] = fmt.Sprintf("%s %s NULL", column, qp.Operator)
+			continue
+		case qp.Operator == OpIn:
+			clauses[i] = fmt.Sprintf("%s = ANY($%d)", column, paramIdx)
+		default:
+			clauses[i] = fmt.Sprintf("%s %s $%d", column, qp.Operator, paramIdx)
+		}
+		args = append(args, qp.Value)
+		paramIdx++
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+// Upsert inserts data or resolves the conflict with an existing income as
+// configured by u. data is refreshed with the stored row, unless the result
+// is UpsertSkipped.
+func (f *Facade) Upsert(
+	ctx context.Context,
+	data *Data,
+	u Upsert,
+) (UpsertResult, error) {
+	return f.UpsertTx(ctx, f.executor(ctx), data, u)
+}
+
+func (f *Facade) UpsertTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	data *Data,
+	u Upsert,
+) (UpsertResult, error) {
+	if err := validateData(data); err != nil {
+		return "", err
+	}
+	f.generate(data)
+	defaultCurrency(data)
+	defaultVersion(data)
+
+	query, whereArgs, err := upsertQuery(u)
+	if err != nil {
+		return "", err
+	}
+	// xmax is 0 only for a row version created by an insert
+	query += " RETURNING (xmax = 0), " + strings.Join(allStringFields, ", ")
+	args := append(GetValues(data), whereArgs...)
+
+	var (
+		stored   Data
+		inserted bool
+	)
+	err = m_audit.Run(ctx, tx, func(ctx context.Context, tx m_options.Executor) error {
+		return stored.scan(leadingScanner{tx.QueryRow(ctx, query, args...), &inserted}, allFieldsList)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return UpsertSkipped, nil
+	}
+	if err != nil {
+		f.logError("UpsertTx", "Failed to QueryRow", logger.H{
+			"error": err,
+			"data":  data,
+		})
+		return "", m_errors.Map(err)
+	}
+
+	*data = stored
+	if inserted {
+		return UpsertInserted, nil
+	}
+	return UpsertUpdated, nil
+}
+
+// leadingScanner scans the first column into dest and the rest as asked.
+type leadingScanner struct {
+	row  rowScanner
+	dest any
+}
+
+func (s leadingScanner) Scan(dest ...any) error {
+	return s.row.Scan(append([]any{s.dest}, dest...)...)
+}
@@ -0,0 +1,40 @@
+package m_income
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/m_types"
+	"github.com/rsmrtk/db-fd-model/sql_builder"
+)
+
+func TestValidateUpdateExpressions(t *testing.T) {
+	negative := m_types.MustParseDecimal("-5")
+	positive := m_types.MustParseDecimal("5")
+
+	tests := []struct {
+		name  string
+		data  UpdateFields
+		valid bool
+	}{
+		{"coalesce with invalid literal", UpdateFields{IncomeAmount: sql_builder.Coalesce(&negative)}, false},
+		{"if null with invalid literal", UpdateFields{IncomeAmount: sql_builder.IfNull(negative)}, false},
+		{"coalesce with valid literal", UpdateFields{IncomeAmount: sql_builder.Coalesce(&positive)}, true},
+		{"coalesce with NULL keeps the column", UpdateFields{IncomeName: sql_builder.Coalesce((*string)(nil))}, true},
+		{"decrement is left to the CHECK", UpdateFields{IncomeAmount: sql_builder.Decr(positive)}, true},
+		{"expression on the primary key", UpdateFields{IncomeID: sql_builder.Default()}, false},
+		{"expression on version", UpdateFields{Version: sql_builder.Incr(1)}, false},
+		{"expression on created_at", UpdateFields{CreatedAt: sql_builder.Now()}, false},
+		{"expression on deleted_at", UpdateFields{DeletedAt: sql_builder.Now()}, false},
+	}
+	for _, tt := range tests {
+		err := validateUpdate(tt.data)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, m_errors.ErrValidation) {
+			t.Errorf("%s: got %v, want ErrValidation", tt.name, err)
+		}
+	}
+}
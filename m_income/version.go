@@ -0,0 +1,141 @@
+package m_income
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// Every write increments the version column. UpdateVersion applies an update
+// only if the income is still at the version the caller read, e.g. from an
+// If-Match header, and otherwise fails with m_errors.ErrStaleVersion. Update,
+// UpdateReturning and OperationWrite.Update do the same for an update built
+// with UpdateBuilder.ExpectVersion, or UpdateFields with a Version, and
+// UpdateByParams then only updates the incomes at that version. Without an
+// expected version they write whatever version the row is at.
+
+// defaultVersion starts an income written by CreateOrUpdate at version 1.
+func defaultVersion(data *Data) {
+	if data.Version == 0 {
+		data.Version = 1
+	}
+}
+
+// expectedVersion returns the version update expects the row to be at, 0
+// when it applies at any version.
+func expectedVersion(data UpdateFields) int64 {
+	switch v := data[Version].(type) {
+	case int64:
+		return v
+	case *int64:
+		if v != nil {
+			return *v
+		}
+	}
+	return 0
+}
+
+// versionQuery adds the expected version to an UPDATE built by updateQuery.
+func (f *Facade) versionQuery(incomeID string, version int64, data UpdateFields) (string, []interface{}) {
+	query, args := updateQuery(incomeID, data)
+	args = append(args, version)
+	return f.andScope(query) + fmt.Sprintf(" AND %s = $%d", Version, len(args)), args
+}
+
+// staleVersion explains why a versioned update matched no row: the income
+// is missing, or it is at another version.
+func (f *Facade) staleVersion(
+	ctx context.Context,
+	functionName string,
+	ex m_options.Executor,
+	incomeID string,
+	version int64,
+) error {
+	current, err := f.find(ctx, functionName, ex, incomeID, []Field{Version})
+	if err != nil {
+		return err
+	}
+	return &m_errors.Error{
+		Kind:   m_errors.ErrStaleVersion,
+		Table:  Table,
+		Column: Version.String(),
+		Err:    fmt.Errorf("income %s is at version %d, not %d", incomeID, current.Version, version),
+	}
+}
+
+// UpdateVersion is like Update but only applies when the income is at
+// version. It returns an error matching m_errors.ErrStaleVersion when the
+// income has been written since, and m_errors.ErrNotFound when it is gone.
+func (f *Facade) UpdateVersion(
+	ctx context.Context,
+	incomeID string,
+	version int64,
+	update Updater,
+) error {
+	return f.UpdateVersionTx(ctx, f.executor(ctx), incomeID, version, update)
+}
+
+func (f *Facade) UpdateVersionTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	incomeID string,
+	version int64,
+	update Updater,
+) error {
+	data := fieldsOf(update)
+	if err := validateUpdate(data); err != nil {
+		return err
+	}
+
+	query, args := f.versionQuery(incomeID, version, data)
+	tag, err := f.exec(ctx, tx, query, args...)
+	if err != nil {
+		f.logError("UpdateVersionTx", "Failed to Exec", logger.H{
+			"error": err, "income_id": incomeID, "version": version,
+			"data": data,
+		})
+		return m_errors.Map(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return f.staleVersion(ctx, "UpdateVersionTx", tx, incomeID, version)
+	}
+	return nil
+}
+
+// UpdateVersionReturning is like UpdateVersion but returns the updated
+// income, whose Version is the one to hand out as the new ETag.
+func (f *Facade) UpdateVersionReturning(
+	ctx context.Context,
+	incomeID string,
+	version int64,
+	update Updater,
+	fields []Field,
+) (*Data, error) {
+	return f.UpdateVersionReturningTx(ctx, f.executor(ctx), incomeID, version, update, fields)
+}
+
+func (f *Facade) UpdateVersionReturningTx(
+	ctx context.Context,
+	tx m_options.Executor,
+	incomeID string,
+	version int64,
+	update Updater,
+	fields []Field,
+) (*Data, error) {
+	data := fieldsOf(update)
+	if err := validateUpdate(data); err != nil {
+		return nil, err
+	}
+
+	fields = returningFields(fields)
+	query, args := f.versionQuery(incomeID, version, data)
+	res, err := f.queryRow(ctx, "UpdateVersionReturningTx", tx, fields, query+returning(fields), args...)
+	if errors.Is(err, m_errors.ErrNotFound) {
+		return nil, f.staleVersion(ctx, "UpdateVersionReturningTx", tx, incomeID, version)
+	}
+	return res, err
+}
@@ -0,0 +1,263 @@
+package m_migrate
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	Package = "m_migrate"
+	Table   = "schema_migrations"
+
+	// lockKey is the pg_advisory_lock key held while migrating, so concurrent
+	// deploys apply each migration exactly once.
+	lockKey int64 = 0x66645f6d6f64656c // "fd_model"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// Migration is one versioned schema step.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes a migration and when it was applied, if it was.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrations returns the embedded migrations ordered by version.
+func Migrations() ([]Migration, error) {
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration, len(files)/2)
+	for _, file := range files {
+		base := path.Base(file)
+
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("%s: migration %s must end in .up.sql or .down.sql", Package, base)
+		}
+
+		stem := strings.TrimSuffix(base, "."+direction+".sql")
+		versionStr, name, ok := strings.Cut(stem, "_")
+		if !ok {
+			return nil, fmt.Errorf("%s: migration %s must be named <version>_<name>", Package, base)
+		}
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: migration %s has an invalid version: %w", Package, base, err)
+		}
+
+		body, err := migrationsFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, found := byVersion[version]
+		if !found {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%s: migration %d_%s has no up step", Package, m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Up applies every pending migration, each in its own transaction, and
+// returns the versions it applied.
+func Up(ctx context.Context, db *pgxpool.Pool) ([]int64, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []int64
+	err = withLock(ctx, db, func(conn *pgxpool.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := done[m.Version]; ok {
+				continue
+			}
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, m.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx,
+					fmt.Sprintf("INSERT INTO %s (version, name) VALUES ($1, $2)", Table),
+					m.Version, m.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("%s: migration %d_%s up: %w", Package, m.Version, m.Name, err)
+			}
+			applied = append(applied, m.Version)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the latest steps applied migrations and returns the
+// versions it rolled back.
+func Down(ctx context.Context, db *pgxpool.Pool, steps int) ([]int64, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []int64
+	err = withLock(ctx, db, func(conn *pgxpool.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			m := migrations[i]
+			if _, ok := done[m.Version]; !ok {
+				continue
+			}
+			if m.Down == "" {
+				return fmt.Errorf("%s: migration %d_%s has no down step", Package, m.Version, m.Name)
+			}
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, m.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx,
+					fmt.Sprintf("DELETE FROM %s WHERE version = $1", Table), m.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("%s: migration %d_%s down: %w", Package, m.Version, m.Name, err)
+			}
+			reverted = append(reverted, m.Version)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// GetStatus lists every embedded migration with the time it was applied.
+// It only reads schema_migrations and does not take the advisory lock, so
+// it answers while another process is migrating.
+func GetStatus(ctx context.Context, db *pgxpool.Pool) ([]Status, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	if err := db.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", Table).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("%s: failed to look up %s: %w", Package, Table, err)
+	}
+
+	done := make(map[int64]time.Time)
+	if exists {
+		if done, err = appliedVersions(ctx, db); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]Status, len(migrations))
+	for i, m := range migrations {
+		out[i] = Status{Version: m.Version, Name: m.Name}
+		if at, ok := done[m.Version]; ok {
+			out[i].AppliedAt = &at
+		}
+	}
+	return out, nil
+}
+
+// withLock runs fn on a single connection holding the migration advisory
+// lock, after making sure the schema_migrations table exists.
+func withLock(ctx context.Context, db *pgxpool.Pool, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: failed to acquire connection: %w", Package, err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("%s: failed to take advisory lock: %w", Package, err)
+	}
+	defer conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	_, err = conn.Exec(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			version    BIGINT NOT NULL PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`, Table))
+	if err != nil {
+		return fmt.Errorf("%s: failed to create %s: %w", Package, Table, err)
+	}
+
+	return fn(conn)
+}
+
+// querier is implemented by *pgxpool.Pool and *pgxpool.Conn.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func appliedVersions(ctx context.Context, q querier) (map[int64]time.Time, error) {
+	rows, err := q.Query(ctx, fmt.Sprintf("SELECT version, applied_at FROM %s", Table))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read %s: %w", Package, Table, err)
+	}
+	defer rows.Close()
+
+	done := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = appliedAt
+	}
+	return done, rows.Err()
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS expenses;
+DROP TYPE IF EXISTS expense_type_enum;
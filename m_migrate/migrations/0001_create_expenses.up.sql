@@ -0,0 +1,72 @@
+-- Databases created from the .sql files that predate migrations already have
+-- an expenses table, so every statement here skips what exists and the block
+-- below converts the old column types.
+
+-- Create ENUM type for expense types
+DO $$
+BEGIN
+    CREATE TYPE expense_type_enum AS ENUM (
+        'food',
+        'restaurants',
+        'entertainment',
+        'dwelling',
+        'utilities',
+        'household_purchases',
+        'transfer',
+        'others'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+-- Create expenses table
+CREATE TABLE IF NOT EXISTS expenses
+(
+    expense_id     UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    expense_name   VARCHAR(255) NOT NULL,
+    expense_amount DECIMAL(15, 2),
+    expense_type   expense_type_enum,
+    expense_date   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The expenses table of db.sql had a VARCHAR(36) id and untyped columns.
+-- Convert such a table to the types above; expense_id must hold UUIDs and
+-- expense_type one of the enum labels, where db.sql wrote
+-- 'household purchases' with a space. Timestamps without a time zone are
+-- read in the session TimeZone.
+DO $$
+BEGIN
+    IF (SELECT data_type
+        FROM information_schema.columns
+        WHERE table_schema = current_schema()
+          AND table_name = 'expenses'
+          AND column_name = 'expense_id') <> 'uuid' THEN
+        ALTER TABLE expenses
+            ALTER COLUMN expense_id TYPE UUID USING expense_id::uuid,
+            ALTER COLUMN expense_id SET DEFAULT gen_random_uuid(),
+            ALTER COLUMN expense_amount TYPE DECIMAL(15, 2),
+            ALTER COLUMN expense_type TYPE expense_type_enum
+                USING replace(expense_type, ' ', '_')::expense_type_enum,
+            ALTER COLUMN expense_date TYPE TIMESTAMP WITH TIME ZONE,
+            ALTER COLUMN expense_date SET DEFAULT CURRENT_TIMESTAMP,
+            ALTER COLUMN created_at TYPE TIMESTAMP WITH TIME ZONE,
+            ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
+    END IF;
+END
+$$;
+
+-- Create indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_expenses_date ON expenses(expense_date);
+CREATE INDEX IF NOT EXISTS idx_expenses_type ON expenses(expense_type);
+CREATE INDEX IF NOT EXISTS idx_expenses_amount ON expenses(expense_amount);
+
+-- Add comments for documentation
+COMMENT ON TABLE expenses IS 'Table to store expense records';
+COMMENT ON COLUMN expenses.expense_id IS 'Unique identifier for expense (UUID)';
+COMMENT ON COLUMN expenses.expense_name IS 'Name/description of the expense';
+COMMENT ON COLUMN expenses.expense_amount IS 'Amount of the expense';
+COMMENT ON COLUMN expenses.expense_type IS 'Category/type of expense';
+COMMENT ON COLUMN expenses.expense_date IS 'Date when expense occurred';
+COMMENT ON COLUMN expenses.created_at IS 'Record creation timestamp';
@@ -0,0 +1,64 @@
+-- Databases created from the .sql files that predate migrations already have
+-- an incomes table, so every statement here skips what exists and the block
+-- below converts the old column types.
+
+-- Create ENUM type for income types
+DO $$
+BEGIN
+    CREATE TYPE income_type_enum AS ENUM (
+        'salary',
+        'transfer',
+        'others'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+-- Create incomes table
+CREATE TABLE IF NOT EXISTS incomes
+(
+    income_id     UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    income_name   VARCHAR(255),
+    income_amount DECIMAL(15, 2),
+    income_type   income_type_enum,
+    income_date   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at    TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The incomes table of m_income.sql had a VARCHAR(36) id and untyped
+-- columns. Convert such a table to the types above; income_id must hold
+-- UUIDs and income_type one of the enum labels. Timestamps without a time
+-- zone are read in the session TimeZone.
+DO $$
+BEGIN
+    IF (SELECT data_type
+        FROM information_schema.columns
+        WHERE table_schema = current_schema()
+          AND table_name = 'incomes'
+          AND column_name = 'income_id') <> 'uuid' THEN
+        ALTER TABLE incomes
+            ALTER COLUMN income_id TYPE UUID USING income_id::uuid,
+            ALTER COLUMN income_id SET DEFAULT gen_random_uuid(),
+            ALTER COLUMN income_amount TYPE DECIMAL(15, 2),
+            ALTER COLUMN income_type TYPE income_type_enum USING income_type::income_type_enum,
+            ALTER COLUMN income_date TYPE TIMESTAMP WITH TIME ZONE,
+            ALTER COLUMN income_date SET DEFAULT CURRENT_TIMESTAMP,
+            ALTER COLUMN created_at TYPE TIMESTAMP WITH TIME ZONE,
+            ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
+    END IF;
+END
+$$;
+
+-- Create indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_incomes_date ON incomes(income_date);
+CREATE INDEX IF NOT EXISTS idx_incomes_type ON incomes(income_type);
+
+-- Add comments for documentation
+COMMENT ON TABLE incomes IS 'Table to store income records';
+COMMENT ON COLUMN incomes.income_id IS 'Unique identifier for income (UUID)';
+COMMENT ON COLUMN incomes.income_name IS 'Name/description of the income';
+COMMENT ON COLUMN incomes.income_amount IS 'Amount of the income';
+COMMENT ON COLUMN incomes.income_type IS 'Category/type of income';
+COMMENT ON COLUMN incomes.income_date IS 'Date when income was received';
+COMMENT ON COLUMN incomes.created_at IS 'Record creation timestamp';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS currency_rates;
+ALTER TABLE expenses DROP COLUMN IF EXISTS expense_currency;
+ALTER TABLE incomes DROP COLUMN IF EXISTS income_currency;
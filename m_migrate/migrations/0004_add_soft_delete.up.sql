@@ -0,0 +1,10 @@
+-- Mark rows as deleted instead of removing them, for tables with soft delete enabled
+ALTER TABLE incomes ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE expenses ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Purge scans deleted rows by age
+CREATE INDEX idx_incomes_deleted_at ON incomes(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_expenses_deleted_at ON expenses(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN incomes.deleted_at IS 'Soft delete timestamp, NULL while the record is live';
+COMMENT ON COLUMN expenses.deleted_at IS 'Soft delete timestamp, NULL while the record is live';
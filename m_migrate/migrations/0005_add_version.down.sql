@@ -0,0 +1,2 @@
+ALTER TABLE expenses DROP COLUMN IF EXISTS version;
+ALTER TABLE incomes DROP COLUMN IF EXISTS version;
@@ -0,0 +1,6 @@
+-- Row version for optimistic concurrency, incremented by every write
+ALTER TABLE incomes ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE expenses ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN incomes.version IS 'Row version, incremented on every write';
+COMMENT ON COLUMN expenses.version IS 'Row version, incremented on every write';
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS expenses_audit ON expenses;
+DROP TRIGGER IF EXISTS incomes_audit ON incomes;
+DROP FUNCTION IF EXISTS audit_row_change();
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,2 @@
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS expenses_expense_amount_positive;
+ALTER TABLE incomes DROP CONSTRAINT IF EXISTS incomes_income_amount_positive;
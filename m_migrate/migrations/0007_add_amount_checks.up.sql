@@ -0,0 +1,7 @@
+-- Amounts must be positive, as the facades' Positive rule requires. The
+-- database enforces it too, so expression updates such as
+-- expense_amount = expense_amount - $1 cannot store a non-positive amount.
+-- NOT VALID checks new writes only and leaves existing rows to be cleaned up
+-- and validated with VALIDATE CONSTRAINT.
+ALTER TABLE incomes ADD CONSTRAINT incomes_income_amount_positive CHECK (income_amount > 0) NOT VALID;
+ALTER TABLE expenses ADD CONSTRAINT expenses_expense_amount_positive CHECK (expense_amount > 0) NOT VALID;
@@ -0,0 +1,49 @@
+package m_options
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Executor runs statements against PostgreSQL. It is satisfied by
+// *pgxpool.Pool, *pgxpool.Conn, *pgx.Conn and pgx.Tx, so every facade
+// method works the same way on a pool, a single connection or a transaction.
+type Executor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type executorKey struct{}
+
+// WithExecutor returns a context that makes facade methods run on ex
+// instead of the shared pool.
+func WithExecutor(ctx context.Context, ex Executor) context.Context {
+	return context.WithValue(ctx, executorKey{}, ex)
+}
+
+// ExecutorFromContext returns the executor stored by WithExecutor, if any.
+func ExecutorFromContext(ctx context.Context) (Executor, bool) {
+	ex, ok := ctx.Value(executorKey{}).(Executor)
+	return ex, ok && ex != nil
+}
+
+// Beginner starts a unit of work on an executor. On *pgxpool.Pool and
+// *pgx.Conn it begins a transaction; on a pgx.Tx it creates a SAVEPOINT,
+// whose Commit releases it and whose Rollback rolls back to it.
+type Beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Begin starts a transaction on ex, or a savepoint when ex is already a
+// transaction.
+func Begin(ctx context.Context, ex Executor) (pgx.Tx, error) {
+	b, ok := ex.(Beginner)
+	if !ok {
+		return nil, fmt.Errorf("executor %T cannot begin a transaction", ex)
+	}
+	return b.Begin(ctx)
+}
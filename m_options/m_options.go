@@ -1,19 +1,36 @@
 package m_options
 
 import (
-	"database/sql"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rsmrtk/smartlg/logger"
 )
 
 type Options struct {
 	Log *logger.Logger
-	DB  *sql.DB
+	DB  *pgxpool.Pool
+
+	// Router sends reads to replicas; nil keeps every read on DB
+	Router *Router
+
+	// Clock stamps created_at and generated ids on Create; nil means time.Now
+	Clock func() time.Time
+
+	// SoftDelete lists the tables whose Delete sets deleted_at instead of
+	// removing the row
+	SoftDelete []string
+}
+
+// SoftDeletes reports whether table uses soft delete.
+func (o Options) SoftDeletes(table string) bool {
+	return slices.Contains(o.SoftDelete, table)
 }
 
 func (o Options) IsValid() error {
-	if o == (Options{}) {
+	if o.Log == nil && o.DB == nil && o.Router == nil && o.Clock == nil && o.SoftDelete == nil {
 		return fmt.Errorf("options is empty")
 	}
 	if o.Log == nil {
@@ -0,0 +1,146 @@
+package m_options
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReplicaPolicy selects which replica serves a read.
+type ReplicaPolicy string
+
+const (
+	PolicyRoundRobin       ReplicaPolicy = "round_robin"       // Rotate through replicas
+	PolicyLeastConnections ReplicaPolicy = "least_connections" // Replica with the fewest acquired connections
+	PolicyLagAware         ReplicaPolicy = "lag_aware"         // Least lagging replica within MaxLag
+)
+
+// Replica is a read-only pool together with its last measured lag.
+type Replica struct {
+	Pool *pgxpool.Pool
+
+	lag     atomic.Int64 // nanoseconds
+	healthy atomic.Bool
+}
+
+// Lag returns the replication lag measured by the last RefreshLag call and
+// whether the replica answered it.
+func (r *Replica) Lag() (time.Duration, bool) {
+	return time.Duration(r.lag.Load()), r.healthy.Load()
+}
+
+// Router sends reads to replicas according to a policy. Writes, explicit
+// executors and contexts marked with WithPrimary stay on the primary.
+type Router struct {
+	primary  *pgxpool.Pool
+	replicas []*Replica
+	policy   ReplicaPolicy
+	maxLag   time.Duration
+	next     atomic.Uint64
+}
+
+// NewRouter returns a router over replicas. An empty policy means
+// round-robin; maxLag is only used by PolicyLagAware.
+func NewRouter(primary *pgxpool.Pool, replicas []*pgxpool.Pool, policy ReplicaPolicy, maxLag time.Duration) *Router {
+	if policy == "" {
+		policy = PolicyRoundRobin
+	}
+	r := &Router{
+		primary:  primary,
+		replicas: make([]*Replica, len(replicas)),
+		policy:   policy,
+		maxLag:   maxLag,
+	}
+	for i, pool := range replicas {
+		r.replicas[i] = &Replica{Pool: pool}
+		r.replicas[i].healthy.Store(true)
+	}
+	return r
+}
+
+// Replicas returns the replicas in configuration order.
+func (r *Router) Replicas() []*Replica {
+	return r.replicas
+}
+
+// Reader returns the executor that should serve a read.
+func (r *Router) Reader() Executor {
+	if len(r.replicas) == 0 {
+		return r.primary
+	}
+
+	switch r.policy {
+	case PolicyLeastConnections:
+		best := r.replicas[0]
+		for _, rep := range r.replicas[1:] {
+			if rep.Pool.Stat().AcquiredConns() < best.Pool.Stat().AcquiredConns() {
+				best = rep
+			}
+		}
+		return best.Pool
+	case PolicyLagAware:
+		var best *Replica
+		var bestLag time.Duration
+		for _, rep := range r.replicas {
+			lag, ok := rep.Lag()
+			if !ok || (r.maxLag > 0 && lag > r.maxLag) {
+				continue
+			}
+			if best == nil || lag < bestLag {
+				best, bestLag = rep, lag
+			}
+		}
+		if best == nil {
+			return r.primary
+		}
+		return best.Pool
+	default:
+		n := r.next.Add(1) - 1
+		return r.replicas[n%uint64(len(r.replicas))].Pool
+	}
+}
+
+// RefreshLag measures the replay lag of every replica. A replica that fails
+// the check is skipped by PolicyLagAware until it answers again.
+func (r *Router) RefreshLag(ctx context.Context) {
+	for _, rep := range r.replicas {
+		lag, err := ReplicaLag(ctx, rep.Pool)
+		if err != nil {
+			rep.healthy.Store(false)
+			continue
+		}
+		rep.lag.Store(int64(lag))
+		rep.healthy.Store(true)
+	}
+}
+
+// ReplicaLag returns how far a replica's replay is behind the primary. It
+// is zero on a primary or on a replica that has replayed everything.
+func ReplicaLag(ctx context.Context, ex Executor) (time.Duration, error) {
+	const query = `SELECT CASE
+		WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`
+
+	var seconds float64
+	if err := ex.QueryRow(ctx, query).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+type primaryKey struct{}
+
+// WithPrimary forces reads made with the returned context onto the primary,
+// e.g. to read your own writes right after committing them.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// UsePrimary reports whether ctx was marked with WithPrimary.
+func UsePrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
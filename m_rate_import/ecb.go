@@ -0,0 +1,64 @@
+package m_rate_import
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rsmrtk/db-fd-model/m_currency_rate"
+	"github.com/rsmrtk/db-fd-model/m_types"
+)
+
+// ecbEnvelope matches eurofxref-daily.xml and eurofxref-hist.xml:
+//
+//	<Cube><Cube time="2024-01-05"><Cube currency="USD" rate="1.0921"/>...
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ParseECB reads the ECB euro foreign exchange reference rates. Every rate
+// is stored as 1 EUR = rate currency.
+func ParseECB(r io.Reader) ([]*m_currency_rate.Data, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode ECB XML: %w", err)
+	}
+
+	source := SourceECB
+	var rates []*m_currency_rate.Data
+	for _, day := range envelope.Days {
+		date, err := time.Parse(time.DateOnly, day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("ECB day %q: %w", day.Time, err)
+		}
+		for _, rate := range day.Rates {
+			quote, err := m_types.ParseCurrency(rate.Currency)
+			if err != nil {
+				return nil, fmt.Errorf("ECB %s: %w", day.Time, err)
+			}
+			value, err := m_types.ParseDecimal(rate.Rate)
+			if err != nil {
+				return nil, fmt.Errorf("ECB %s %s: %w", day.Time, quote, err)
+			}
+			rates = append(rates, &m_currency_rate.Data{
+				RateDate:      date,
+				BaseCurrency:  m_types.CurrencyEUR,
+				QuoteCurrency: quote,
+				Rate:          &value,
+				Source:        &source,
+			})
+		}
+	}
+
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("no rates found in ECB XML")
+	}
+	return rates, nil
+}
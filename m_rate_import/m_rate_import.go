@@ -0,0 +1,93 @@
+package m_rate_import
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rsmrtk/db-fd-model/m_currency_rate"
+)
+
+const Package = "m_rate_import"
+
+// Format is the layout of a rates file.
+type Format string
+
+const (
+	FormatECB     Format = "ecb"      // ECB eurofxref XML, daily or historical
+	FormatNBUJSON Format = "nbu_json" // NBU statdirectory/exchange JSON export
+	FormatNBUCSV  Format = "nbu_csv"  // NBU CSV export
+)
+
+// Source values stored with imported rates.
+const (
+	SourceECB = "ECB"
+	SourceNBU = "NBU"
+)
+
+// DetectFormat guesses the format from the file extension: .xml is ECB,
+// .json and .csv are NBU.
+func DetectFormat(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return FormatECB, nil
+	case ".json":
+		return FormatNBUJSON, nil
+	case ".csv":
+		return FormatNBUCSV, nil
+	}
+	return "", fmt.Errorf("%s: cannot detect the format of %s", Package, path)
+}
+
+// Parse reads rates in format from r.
+func Parse(r io.Reader, format Format) ([]*m_currency_rate.Data, error) {
+	switch format {
+	case FormatECB:
+		return ParseECB(r)
+	case FormatNBUJSON:
+		return ParseNBUJSON(r)
+	case FormatNBUCSV:
+		return ParseNBUCSV(r)
+	}
+	return nil, fmt.Errorf("%s: unknown format %q", Package, format)
+}
+
+// ParseFile reads rates from a local file. An empty format is detected from
+// the extension.
+func ParseFile(path string, format Format) ([]*m_currency_rate.Data, error) {
+	if format == "" {
+		var err error
+		if format, err = DetectFormat(path); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", Package, err)
+	}
+	defer file.Close()
+
+	rates, err := Parse(file, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", Package, path, err)
+	}
+	return rates, nil
+}
+
+// ImportFile parses a local rates file and upserts every rate through f in
+// one transaction. Re-importing a file is safe: existing rates for the same
+// date and pair are overwritten. It returns the number of rates written.
+func ImportFile(ctx context.Context, f *m_currency_rate.Facade, path string, format Format) (int, error) {
+	rates, err := ParseFile(path, format)
+	if err != nil {
+		return 0, err
+	}
+	if err := f.CreateOrUpdateMany(ctx, rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
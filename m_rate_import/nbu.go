@@ -0,0 +1,180 @@
+package m_rate_import
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rsmrtk/db-fd-model/m_currency_rate"
+	"github.com/rsmrtk/db-fd-model/m_types"
+)
+
+// nbuRow matches one entry of the NBU statdirectory/exchange JSON export:
+//
+//	{"r030":840,"txt":"Долар США","rate":41.1234,"cc":"USD","exchangedate":"05.01.2024"}
+type nbuRow struct {
+	CC           string      `json:"cc"`
+	Rate         json.Number `json:"rate"`
+	Units        json.Number `json:"units"`
+	ExchangeDate string      `json:"exchangedate"`
+}
+
+// ParseNBUJSON reads the National Bank of Ukraine official rates. Every rate
+// is stored as 1 currency = rate UAH. Numbers are decoded without going
+// through float64.
+func ParseNBUJSON(r io.Reader) ([]*m_currency_rate.Data, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var rows []nbuRow
+	if err := dec.Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to decode NBU JSON: %w", err)
+	}
+
+	rates := make([]*m_currency_rate.Data, 0, len(rows))
+	for i, row := range rows {
+		rate, err := nbuRate(row.ExchangeDate, row.CC, row.Rate.String(), row.Units.String())
+		if err != nil {
+			return nil, fmt.Errorf("NBU JSON entry %d: %w", i, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("no rates found in NBU JSON")
+	}
+	return rates, nil
+}
+
+// CSV headers accepted for each column, as exported by the statdirectory
+// API and by the bank.gov.ua site.
+var nbuHeaders = map[string][]string{
+	"date":  {"exchangedate", "дата"},
+	"cc":    {"cc", "код літерний", "літерний код"},
+	"rate":  {"rate", "офіційний курс"},
+	"units": {"units", "кількість одиниць"},
+}
+
+// ParseNBUCSV reads an NBU CSV export with a header row. Both ',' and ';'
+// separators are accepted; with ';' a decimal comma is allowed in rates.
+// When a units column is present the rate is divided by it.
+func ParseNBUCSV(r io.Reader) ([]*m_currency_rate.Data, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	head = bytes.TrimPrefix(head, []byte("\ufeff"))
+	firstLine, _, _ := bytes.Cut(head, []byte("\n"))
+
+	cr := csv.NewReader(br)
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read NBU CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.Trim(strings.TrimPrefix(name, "\ufeff"), " \""))
+		for key, aliases := range nbuHeaders {
+			for _, alias := range aliases {
+				if name == alias {
+					columns[key] = i
+				}
+			}
+		}
+	}
+	for _, key := range []string{"date", "cc", "rate"} {
+		if _, ok := columns[key]; !ok {
+			return nil, fmt.Errorf("NBU CSV has no %s column", key)
+		}
+	}
+
+	field := func(record []string, key string) string {
+		i, ok := columns[key]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		value := strings.TrimSpace(record[i])
+		if cr.Comma == ';' {
+			value = strings.ReplaceAll(value, ",", ".")
+		}
+		return value
+	}
+
+	var rates []*m_currency_rate.Data
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("NBU CSV line %d: %w", line, err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		rate, err := nbuRate(field(record, "date"), field(record, "cc"), field(record, "rate"), field(record, "units"))
+		if err != nil {
+			return nil, fmt.Errorf("NBU CSV line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("no rates found in NBU CSV")
+	}
+	return rates, nil
+}
+
+func nbuRate(date string, cc string, rate string, units string) (*m_currency_rate.Data, error) {
+	rateDate, err := parseNBUDate(date)
+	if err != nil {
+		return nil, err
+	}
+	base, err := m_types.ParseCurrency(cc)
+	if err != nil {
+		return nil, err
+	}
+	value, err := m_types.ParseDecimal(rate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", base, err)
+	}
+
+	// Rates for small currencies are quoted per 10, 100 or 1000 units
+	if units != "" && units != "1" {
+		n := units[1:]
+		if units[0] != '1' || strings.Trim(n, "0") != "" {
+			return nil, fmt.Errorf("%s: unsupported units %q", base, units)
+		}
+		value = value.Shift(-int32(len(n)))
+	}
+
+	source := SourceNBU
+	return &m_currency_rate.Data{
+		RateDate:      rateDate,
+		BaseCurrency:  base,
+		QuoteCurrency: m_types.CurrencyUAH,
+		Rate:          &value,
+		Source:        &source,
+	}, nil
+}
+
+func parseNBUDate(s string) (time.Time, error) {
+	for _, layout := range []string{"02.01.2006", time.DateOnly, "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
@@ -0,0 +1,187 @@
+package m_schema
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rsmrtk/db-fd-model/m_options"
+)
+
+const Package = "m_schema"
+
+// Column describes how a facade maps one table column.
+type Column struct {
+	Name     string
+	GoType   string   // Go type of the Data field, used in diff messages
+	PgTypes  []string // Accepted information_schema data types; empty accepts any
+	Nullable bool     // Whether the Go field can hold NULL
+	Enum     []string // Expected labels when the column is a PostgreSQL enum
+}
+
+// Table describes the columns a facade reads and writes.
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+// Diff is one difference between a facade and the live schema.
+type Diff struct {
+	Table   string
+	Column  string
+	Message string
+}
+
+func (d Diff) String() string {
+	if d.Column == "" {
+		return fmt.Sprintf("%s %s", d.Table, d.Message)
+	}
+	return fmt.Sprintf("%s.%s %s", d.Table, d.Column, d.Message)
+}
+
+type dbColumn struct {
+	dataType  string
+	udtName   string
+	nullable  bool
+	charLen   *int32
+	precision *int32
+	scale     *int32
+}
+
+// String formats the column type the way psql shows it, e.g. numeric(15,2).
+func (c dbColumn) String() string {
+	switch {
+	case c.dataType == "USER-DEFINED":
+		return c.udtName
+	case c.charLen != nil:
+		return fmt.Sprintf("%s(%d)", c.dataType, *c.charLen)
+	case c.dataType == "numeric" && c.precision != nil && c.scale != nil:
+		return fmt.Sprintf("numeric(%d,%d)", *c.precision, *c.scale)
+	}
+	return c.dataType
+}
+
+// Check compares tables against information_schema and pg_enum in the
+// current schema and returns every difference found.
+func Check(ctx context.Context, ex m_options.Executor, tables ...Table) ([]Diff, error) {
+	var diffs []Diff
+	for _, table := range tables {
+		tableDiffs, err := checkTable(ctx, ex, table)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", Package, table.Name, err)
+		}
+		diffs = append(diffs, tableDiffs...)
+	}
+	return diffs, nil
+}
+
+func checkTable(ctx context.Context, ex m_options.Executor, table Table) ([]Diff, error) {
+	rows, err := ex.Query(ctx, `
+		SELECT column_name, data_type, udt_name, is_nullable = 'YES',
+			character_maximum_length, numeric_precision, numeric_scale
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1`, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	live := make(map[string]dbColumn)
+	for rows.Next() {
+		var name string
+		var c dbColumn
+		if err := rows.Scan(&name, &c.dataType, &c.udtName, &c.nullable, &c.charLen, &c.precision, &c.scale); err != nil {
+			return nil, err
+		}
+		live[name] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(live) == 0 {
+		return []Diff{{Table: table.Name, Message: "does not exist"}}, nil
+	}
+
+	var diffs []Diff
+	known := make(map[string]bool, len(table.Columns))
+	for _, col := range table.Columns {
+		known[col.Name] = true
+
+		c, ok := live[col.Name]
+		if !ok {
+			diffs = append(diffs, Diff{Table: table.Name, Column: col.Name, Message: "does not exist"})
+			continue
+		}
+
+		if len(col.PgTypes) > 0 && !slices.Contains(col.PgTypes, c.dataType) {
+			diffs = append(diffs, Diff{
+				Table: table.Name, Column: col.Name,
+				Message: fmt.Sprintf("is %s, Go uses %s", c, col.GoType),
+			})
+		}
+
+		if c.nullable && !col.Nullable {
+			diffs = append(diffs, Diff{
+				Table: table.Name, Column: col.Name,
+				Message: fmt.Sprintf("is nullable, Go uses non-nullable %s", col.GoType),
+			})
+		}
+
+		if c.dataType == "USER-DEFINED" && len(col.Enum) > 0 {
+			labels, err := enumLabels(ctx, ex, c.udtName)
+			if err != nil {
+				return nil, err
+			}
+			if len(labels) > 0 && !slices.Equal(sorted(labels), sorted(col.Enum)) {
+				diffs = append(diffs, Diff{
+					Table: table.Name, Column: col.Name,
+					Message: fmt.Sprintf("enum %s has values [%s], Go has [%s]",
+						c.udtName, strings.Join(labels, ", "), strings.Join(col.Enum, ", ")),
+				})
+			}
+		}
+	}
+
+	for name, c := range live {
+		if !known[name] {
+			diffs = append(diffs, Diff{
+				Table: table.Name, Column: name,
+				Message: fmt.Sprintf("(%s) is not mapped by the facade", c),
+			})
+		}
+	}
+
+	slices.SortFunc(diffs, func(a, b Diff) int { return strings.Compare(a.Column, b.Column) })
+	return diffs, nil
+}
+
+func enumLabels(ctx context.Context, ex m_options.Executor, typeName string) ([]string, error) {
+	rows, err := ex.Query(ctx, `
+		SELECT e.enumlabel
+		FROM pg_enum e
+		JOIN pg_type t ON t.oid = e.enumtypid
+		WHERE t.typname = $1
+		ORDER BY e.enumsortorder`, typeName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var labels []string
+	for rows.Next() {
+		var label string
+		if err := rows.Scan(&label); err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	return labels, rows.Err()
+}
+
+func sorted(values []string) []string {
+	out := slices.Clone(values)
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,49 @@
+package m_types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 alphabetic code such as "UAH".
+type Currency string
+
+const (
+	CurrencyUAH Currency = "UAH"
+	CurrencyEUR Currency = "EUR"
+	CurrencyUSD Currency = "USD"
+
+	// DefaultCurrency is used when a row is written without a currency. It
+	// matches the column defaults in the migrations.
+	DefaultCurrency = CurrencyUAH
+)
+
+var ErrInvalidCurrency = errors.New("invalid currency")
+
+// ParseCurrency upper-cases s and checks that it is a three-letter code.
+func ParseCurrency(s string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(s)))
+	if !c.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, s)
+	}
+	return c, nil
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+// IsValid reports whether c has the shape of an ISO 4217 code: three
+// upper-case ASCII letters.
+func (c Currency) IsValid() bool {
+	if len(c) != 3 {
+		return false
+	}
+	for i := 0; i < len(c); i++ {
+		if c[i] < 'A' || c[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,265 @@
+package m_types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const Package = "m_types"
+
+// Decimal is an exact base-10 number, coef × 10^exp. It scans from and
+// encodes to NUMERIC without going through float64. The zero value is 0.
+// Decimals are immutable: arithmetic returns a new value.
+type Decimal struct {
+	coef *big.Int
+	exp  int32
+}
+
+// Money is the type of every amount column.
+type Money = Decimal
+
+// MaxExponent bounds the exponent of a parsed or scanned Decimal, as
+// PostgreSQL NUMERIC bounds its scale. It keeps String and the arithmetic
+// from expanding untrusted input such as "1e2000000000" into billions of
+// digits.
+const MaxExponent = 16383
+
+var (
+	bigTen = big.NewInt(10)
+
+	ErrInvalidDecimal = errors.New("invalid decimal")
+)
+
+// NewDecimal returns coef × 10^exp, e.g. NewDecimal(1250, -2) is 12.50.
+func NewDecimal(coef int64, exp int32) Decimal {
+	return Decimal{coef: big.NewInt(coef), exp: exp}
+}
+
+// NewFromInt returns v as a Decimal.
+func NewFromInt(v int64) Decimal {
+	return NewDecimal(v, 0)
+}
+
+// ParseDecimal parses a plain or exponent notation number such as "12.50",
+// "-3" or "1.5e3". The number of fractional digits is preserved. The
+// resulting exponent must lie within ±MaxExponent.
+func ParseDecimal(s string) (Decimal, error) {
+	orig := s
+
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, orig)
+		}
+		exp, s = e, s[:i]
+	}
+
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg, s = s[0] == '-', s[1:]
+	}
+
+	intPart, frac, _ := strings.Cut(s, ".")
+	digits := intPart + frac
+	if digits == "" {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, orig)
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, orig)
+		}
+	}
+
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+
+	exp -= int64(len(frac))
+	if exp < -MaxExponent || exp > MaxExponent {
+		return Decimal{}, fmt.Errorf("%w: %q exponent out of range", ErrInvalidDecimal, orig)
+	}
+	return Decimal{coef: coef, exp: int32(exp)}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on invalid input. It is
+// meant for constants.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale returns the coefficient of d at exp, which must not exceed d.exp.
+func (d Decimal) rescale(exp int32) *big.Int {
+	if exp == d.exp {
+		return new(big.Int).Set(d.int())
+	}
+	scale := new(big.Int).Exp(bigTen, big.NewInt(int64(d.exp-exp)), nil)
+	return scale.Mul(scale, d.int())
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	exp := min(a.exp, b.exp)
+	return a.rescale(exp), b.rescale(exp), exp
+}
+
+// Add returns d + o.
+func (d Decimal) Add(o Decimal) Decimal {
+	x, y, exp := align(d, o)
+	return Decimal{coef: x.Add(x, y), exp: exp}
+}
+
+// Sub returns d - o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	x, y, exp := align(d, o)
+	return Decimal{coef: x.Sub(x, y), exp: exp}
+}
+
+// Mul returns d × o.
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.int(), o.int()), exp: d.exp + o.exp}
+}
+
+// Shift returns d × 10^n exactly, e.g. Shift(-2) divides by 100.
+func (d Decimal) Shift(n int32) Decimal {
+	return Decimal{coef: new(big.Int).Set(d.int()), exp: d.exp + n}
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.int()), exp: d.exp}
+}
+
+// Round rounds d to places fractional digits, half away from zero, the way
+// PostgreSQL rounds NUMERIC. Values with fewer digits are returned as is.
+func (d Decimal) Round(places int32) Decimal {
+	if d.exp >= -places {
+		return d
+	}
+
+	scale := new(big.Int).Exp(bigTen, big.NewInt(int64(-places-d.exp)), nil)
+	q, r := new(big.Int).QuoRem(d.int(), scale, new(big.Int))
+
+	// Round up when 2|r| >= scale
+	r.Abs(r).Lsh(r, 1)
+	if r.Cmp(scale) >= 0 {
+		q.Add(q, big.NewInt(int64(d.int().Sign())))
+	}
+	return Decimal{coef: q, exp: -places}
+}
+
+// Cmp returns -1, 0 or +1 depending on whether d is less than, equal to or
+// greater than o. 1.5 and 1.50 compare equal.
+func (d Decimal) Cmp(o Decimal) int {
+	x, y, _ := align(d, o)
+	return x.Cmp(y)
+}
+
+// Equal reports whether d and o are the same number.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// Sign returns -1, 0 or +1.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// String formats d in plain notation keeping its scale, e.g. "12.50".
+func (d Decimal) String() string {
+	coef := d.int()
+	digits := new(big.Int).Abs(coef).String()
+
+	var sb strings.Builder
+	if coef.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+
+	switch {
+	case d.exp >= 0:
+		sb.WriteString(digits)
+		if coef.Sign() != 0 {
+			sb.WriteString(strings.Repeat("0", int(d.exp)))
+		}
+	case len(digits) > int(-d.exp):
+		point := len(digits) + int(d.exp)
+		sb.WriteString(digits[:point])
+		sb.WriteByte('.')
+		sb.WriteString(digits[point:])
+	default:
+		sb.WriteString("0.")
+		sb.WriteString(strings.Repeat("0", int(-d.exp)-len(digits)))
+		sb.WriteString(digits)
+	}
+	return sb.String()
+}
+
+// MarshalJSON encodes d as a JSON number with its exact digits.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one. null leaves
+// d unchanged.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	s := string(b)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// ScanNumeric implements pgtype.NumericScanner. Use *Decimal to scan
+// nullable columns.
+func (d *Decimal) ScanNumeric(v pgtype.Numeric) error {
+	if !v.Valid {
+		return fmt.Errorf("%s: cannot scan NULL into Decimal", Package)
+	}
+	if v.NaN || v.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("%s: cannot scan NaN or infinity into Decimal", Package)
+	}
+	if v.Exp < -MaxExponent || v.Exp > MaxExponent {
+		return fmt.Errorf("%w: exponent %d out of range", ErrInvalidDecimal, v.Exp)
+	}
+	coef := new(big.Int)
+	if v.Int != nil {
+		coef.Set(v.Int)
+	}
+	*d = Decimal{coef: coef, exp: v.Exp}
+	return nil
+}
+
+// NumericValue implements pgtype.NumericValuer.
+func (d Decimal) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: new(big.Int).Set(d.int()), Exp: d.exp, Valid: true}, nil
+}
@@ -0,0 +1,32 @@
+package m_types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestDecimalRejectsHugeExponents(t *testing.T) {
+	for _, in := range []string{`1e2000000000`, `"1e-20000"`, `1e16384`, `0.5e-16383`} {
+		var d Decimal
+		if err := json.Unmarshal([]byte(in), &d); !errors.Is(err, ErrInvalidDecimal) {
+			t.Errorf("Unmarshal(%s) = %v, want ErrInvalidDecimal", in, err)
+		}
+	}
+
+	var d Decimal
+	if err := d.ScanNumeric(pgtype.Numeric{Int: big.NewInt(1), Exp: MaxExponent + 1, Valid: true}); !errors.Is(err, ErrInvalidDecimal) {
+		t.Errorf("ScanNumeric accepted exponent %d: %v", MaxExponent+1, err)
+	}
+}
+
+func TestDecimalAcceptsExponentBounds(t *testing.T) {
+	for _, in := range []string{"1e16383", "1e-16383", "12.50"} {
+		if _, err := ParseDecimal(in); err != nil {
+			t.Errorf("ParseDecimal(%q): %v", in, err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package m_types
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// UUID is an RFC 9562 UUID stored in a PostgreSQL uuid column. The zero
+// value is the nil UUID and is written as NULL.
+type UUID [16]byte
+
+var ErrInvalidUUID = errors.New("invalid uuid")
+
+// ParseUUID parses the canonical 36-character form, with or without braces
+// or the "urn:uuid:" prefix, and the 32-character hex form.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+
+	switch len(s) {
+	case 45: // urn:uuid:xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+		if s[:9] != "urn:uuid:" {
+			return u, fmt.Errorf("%w: %q", ErrInvalidUUID, s)
+		}
+		s = s[9:]
+	case 38: // {xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx}
+		if s[0] != '{' || s[37] != '}' {
+			return u, fmt.Errorf("%w: %q", ErrInvalidUUID, s)
+		}
+		s = s[1:37]
+	case 32:
+		if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+			return UUID{}, fmt.Errorf("%w: %q", ErrInvalidUUID, s)
+		}
+		return u, nil
+	}
+
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("%w: %q", ErrInvalidUUID, s)
+	}
+	h := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(u[:], []byte(h)); err != nil {
+		return UUID{}, fmt.Errorf("%w: %q", ErrInvalidUUID, s)
+	}
+	return u, nil
+}
+
+// MustParseUUID is like ParseUUID but panics on invalid input.
+func MustParseUUID(s string) UUID {
+	u, err := ParseUUID(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// IsZero reports whether u is the nil UUID.
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+// String returns the canonical lower-case form.
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// MarshalText encodes u in canonical form, so it is a JSON string and can
+// be used as a map key.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText accepts any form ParseUUID does.
+func (u *UUID) UnmarshalText(b []byte) error {
+	v, err := ParseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// ScanUUID implements pgtype.UUIDScanner. NULL scans as the nil UUID.
+func (u *UUID) ScanUUID(v pgtype.UUID) error {
+	if !v.Valid {
+		*u = UUID{}
+		return nil
+	}
+	*u = v.Bytes
+	return nil
+}
+
+// UUIDValue implements pgtype.UUIDValuer.
+func (u UUID) UUIDValue() (pgtype.UUID, error) {
+	return pgtype.UUID{Bytes: u, Valid: !u.IsZero()}, nil
+}
+
+// uuidV7 keeps UUIDs made by this process strictly increasing.
+var uuidV7 struct {
+	sync.Mutex
+	ms  int64
+	seq uint16
+}
+
+// NewUUIDv7 returns a time-ordered version 7 UUID for the current time.
+func NewUUIDv7() UUID {
+	return NewUUIDv7At(time.Now())
+}
+
+// NewUUIDv7At returns a version 7 UUID carrying t in milliseconds, followed
+// by a 12-bit counter and 62 random bits (RFC 9562, section 6.2, method 1).
+// Within one millisecond, or when t goes backwards, the counter is
+// incremented, so UUIDs from one process sort in the order they were made.
+func NewUUIDv7At(t time.Time) UUID {
+	var u UUID
+	_, _ = rand.Read(u[:])
+
+	ms := t.UnixMilli()
+	uuidV7.Lock()
+	if ms > uuidV7.ms {
+		// Start the counter in its lower half to leave room for increments
+		uuidV7.ms = ms
+		uuidV7.seq = uint16(u[6]&0x07)<<8 | uint16(u[7])
+	} else {
+		uuidV7.seq++
+		if uuidV7.seq > 0xfff {
+			uuidV7.ms++
+			uuidV7.seq = 0
+		}
+		ms = uuidV7.ms
+	}
+	seq := uuidV7.seq
+	uuidV7.Unlock()
+
+	u[0] = byte(ms >> 40)
+	u[1] = byte(ms >> 32)
+	u[2] = byte(ms >> 24)
+	u[3] = byte(ms >> 16)
+	u[4] = byte(ms >> 8)
+	u[5] = byte(ms)
+	u[6] = 0x70 | byte(seq>>8)
+	u[7] = byte(seq)
+	u[8] = 0x80 | u[8]&0x3f
+	return u
+}
@@ -0,0 +1,157 @@
+package m_validate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/rsmrtk/db-fd-model/m_errors"
+)
+
+const Package = "m_validate"
+
+// Violation is one rule a field failed.
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+
+	err error
+}
+
+// ValidationError lists every violation found in one Data or UpdateFields.
+// It matches m_errors.ErrValidation, and the errors returned by the failed
+// rules, e.g. m_errors.ErrInvalidEnum for Enum.
+type ValidationError struct {
+	Table      string      `json:"table"`
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = fmt.Sprintf("%s: %s", v.Field, v.Message)
+	}
+	return fmt.Sprintf("%s (table %s): %s", m_errors.ErrValidation, e.Table, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Violations)+1)
+	errs = append(errs, m_errors.ErrValidation)
+	for _, v := range e.Violations {
+		errs = append(errs, v.err)
+	}
+	return errs
+}
+
+// Fields returns the names of the fields that failed, in rule order.
+func (e *ValidationError) Fields() []string {
+	var out []string
+	for _, v := range e.Violations {
+		if len(out) == 0 || out[len(out)-1] != v.Field {
+			out = append(out, v.Field)
+		}
+	}
+	return out
+}
+
+// Rule checks a single field value. Check receives the value as stored in
+// Data or UpdateFields, which may be a pointer; use Deref to unwrap it.
+// Rules other than Required treat NULL as valid.
+type Rule struct {
+	Name  string
+	Check func(value any) error
+}
+
+// Validator holds the rules declared for the fields of one table. It is
+// safe to add rules while other goroutines validate.
+type Validator[F ~string] struct {
+	table string
+
+	mu     sync.RWMutex
+	fields []F
+	rules  map[F][]Rule
+}
+
+// New returns a validator without rules for table.
+func New[F ~string](table string) *Validator[F] {
+	return &Validator[F]{
+		table: table,
+		rules: make(map[F][]Rule),
+	}
+}
+
+// Add appends rules to field. Rules run in the order they were added.
+func (v *Validator[F]) Add(field F, rules ...Rule) *Validator[F] {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if _, ok := v.rules[field]; !ok {
+		v.fields = append(v.fields, field)
+	}
+	v.rules[field] = append(v.rules[field], rules...)
+	return v
+}
+
+// Reset removes every rule of field, e.g. to replace the defaults.
+func (v *Validator[F]) Reset(field F) *Validator[F] {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	delete(v.rules, field)
+	for i, f := range v.fields {
+		if f == field {
+			v.fields = append(v.fields[:i:i], v.fields[i+1:]...)
+			break
+		}
+	}
+	return v
+}
+
+// Validate checks values, keyed by column name, and returns a
+// *ValidationError listing every violation, or nil. Fields missing from
+// values are skipped, so the same rules serve a full Data on insert and a
+// partial UpdateFields on update.
+func (v *Validator[F]) Validate(values map[string]any) error {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var violations []Violation
+	for _, field := range v.fields {
+		value, ok := values[string(field)]
+		if !ok {
+			continue
+		}
+		for _, rule := range v.rules[field] {
+			if err := rule.Check(value); err != nil {
+				violations = append(violations, Violation{
+					Field:   string(field),
+					Rule:    rule.Name,
+					Message: err.Error(),
+					err:     err,
+				})
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Table: v.table, Violations: violations}
+}
+
+// Deref follows pointers and reports whether the value is NULL.
+func Deref(value any) (any, bool) {
+	if value == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	return rv.Interface(), true
+}
@@ -0,0 +1,153 @@
+package m_validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rsmrtk/db-fd-model/m_errors"
+)
+
+// Custom returns a rule named name that runs check on non-NULL values.
+func Custom(name string, check func(value any) error) Rule {
+	return Rule{
+		Name: name,
+		Check: func(value any) error {
+			v, ok := Deref(value)
+			if !ok {
+				return nil
+			}
+			return check(v)
+		},
+	}
+}
+
+// Required rejects NULL and, for strings, blank values.
+func Required() Rule {
+	return Rule{
+		Name: "required",
+		Check: func(value any) error {
+			v, ok := Deref(value)
+			if !ok {
+				return errors.New("is required")
+			}
+			if s, ok := asString(v); ok && strings.TrimSpace(s) == "" {
+				return errors.New("is required")
+			}
+			return nil
+		},
+	}
+}
+
+// MaxLen limits strings to n characters, the way VARCHAR(n) counts them.
+func MaxLen(n int) Rule {
+	return Custom("max_len", func(v any) error {
+		s, ok := asString(v)
+		if !ok {
+			return fmt.Errorf("must be a string, got %T", v)
+		}
+		if utf8.RuneCountInString(s) > n {
+			return fmt.Errorf("must be at most %d characters", n)
+		}
+		return nil
+	})
+}
+
+// Positive requires a number greater than zero. It accepts m_types.Decimal
+// and every Go integer and float kind.
+func Positive() Rule {
+	return Custom("positive", func(v any) error {
+		sign, ok := signOf(v)
+		if !ok {
+			return fmt.Errorf("must be a number, got %T", v)
+		}
+		if sign <= 0 {
+			return errors.New("must be greater than 0")
+		}
+		return nil
+	})
+}
+
+// Enum requires one of values. Failures also match m_errors.ErrInvalidEnum.
+func Enum[E ~string](values ...E) Rule {
+	allowed := make([]string, len(values))
+	for i, e := range values {
+		allowed[i] = string(e)
+	}
+	return Custom("enum", func(v any) error {
+		s, ok := asString(v)
+		if !ok || !slices.Contains(allowed, s) {
+			return fmt.Errorf("%w: must be one of %s", m_errors.ErrInvalidEnum, strings.Join(allowed, ", "))
+		}
+		return nil
+	})
+}
+
+// Valid runs the value's own IsValid method, e.g. m_types.Currency.IsValid.
+func Valid(name string) Rule {
+	return Custom(name, func(v any) error {
+		if !isValid(v) {
+			return fmt.Errorf("%v is not valid", v)
+		}
+		return nil
+	})
+}
+
+func isValid(v any) bool {
+	if iv, ok := v.(interface{ IsValid() bool }); ok {
+		return iv.IsValid()
+	}
+	// IsValid may be declared on the pointer receiver
+	ptr := reflect.New(reflect.TypeOf(v))
+	ptr.Elem().Set(reflect.ValueOf(v))
+	if iv, ok := ptr.Interface().(interface{ IsValid() bool }); ok {
+		return iv.IsValid()
+	}
+	return true
+}
+
+func asString(v any) (string, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.String {
+		return "", false
+	}
+	return rv.String(), true
+}
+
+func signOf(v any) (int, bool) {
+	if s, ok := v.(interface{ Sign() int }); ok {
+		return s.Sign(), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmpZero(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() == 0 {
+			return 0, true
+		}
+		return 1, true
+	case reflect.Float32, reflect.Float64:
+		switch f := rv.Float(); {
+		case f > 0:
+			return 1, true
+		case f < 0:
+			return -1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func cmpZero(n int64) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,24 @@
+package db_fd_model
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rsmrtk/db-fd-model/m_migrate"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// Migrate applies every pending schema migration to the primary, so a fresh
+// database ends up with the schema the facades expect. It is safe to call
+// from several instances at once.
+func (m *Model) Migrate(ctx context.Context) error {
+	applied, err := m_migrate.Up(ctx, m.DB)
+	if err != nil {
+		m.log.Error("[PKG DB] Failed to migrate PostgreSQL.", logger.H{
+			"error":   err,
+			"applied": applied,
+		})
+		return fmt.Errorf("failed to migrate PostgreSQL: %w", err)
+	}
+	return nil
+}
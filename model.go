@@ -2,24 +2,31 @@ package db_fd_model
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmrtk/db-fd-model/m_currency_rate"
 	"github.com/rsmrtk/db-fd-model/m_expense"
 	"github.com/rsmrtk/db-fd-model/m_income"
 	"github.com/rsmrtk/db-fd-model/m_options"
 	"github.com/rsmrtk/smartlg/logger"
-
-	// PostgreSQL driver
-	_ "github.com/lib/pq"
 )
 
 type Model struct {
-	DB *sql.DB
+	DB *pgxpool.Pool
 	//
-	Expense *m_expense.Facade
-	Income  *m_income.Facade
+	CurrencyRate *m_currency_rate.Facade
+	Expense      *m_expense.Facade
+	Income       *m_income.Facade
+
+	log     *logger.Logger
+	txRetry txRetry
+
+	replicas    []*pgxpool.Pool
+	router      *m_options.Router
+	stopMonitor context.CancelFunc
 }
 
 type Options struct {
@@ -28,75 +35,155 @@ type Options struct {
 
 	// Optional connection pool settings
 	MaxOpenConns    int
-	MaxIdleConns    int
+	MinIdleConns    int
 	ConnMaxLifetime time.Duration
 	ConnMaxIdleTime time.Duration
+
+	// Deprecated: pgxpool has no cap on idle connections; it keeps up to
+	// MaxOpenConns and closes those idle for ConnMaxIdleTime. MaxIdleConns is
+	// ignored, and is not the MinIdleConns that keeps connections open.
+	MaxIdleConns int
+
+	// Optional WithTx retry settings for serialization failures and deadlocks
+	TxMaxAttempts     int           // Default 3
+	TxRetryBackoff    time.Duration // Default 50ms, doubled after each attempt
+	TxRetryMaxBackoff time.Duration // Default 1s
+
+	// Optional read replicas. Reads (Get, Find, List, OperationRead) are
+	// routed to them; writes and explicit transactions stay on PostgresURL.
+	ReplicaURLs          []string
+	ReplicaPolicy        m_options.ReplicaPolicy // Default round robin
+	ReplicaMaxLag        time.Duration           // Lag-aware only; 0 means no limit
+	ReplicaCheckInterval time.Duration           // Lag-aware only; default 5s
+
+	// Optional startup behaviour. By default New pings once and fails fast.
+	StartupMaxWait    time.Duration // Keep retrying the initial ping for this long
+	StartupBackoff    time.Duration // Default 200ms, doubled after each attempt, with jitter
+	StartupMaxBackoff time.Duration // Default 5s
+	LazyConnect       bool          // Skip the initial ping and connect on first use
+
+	// Optional check of the facades against information_schema and pg_enum
+	SchemaCheck SchemaCheckMode // Default off
+
+	// Optional clock for created_at and generated ids on Create
+	Clock func() time.Time // Default time.Now
+
+	// Optional soft delete: tables listed here, e.g. m_income.Table, get a
+	// deleted_at timestamp on Delete and are hidden from reads until purged
+	SoftDelete []string
 }
 
 func New(ctx context.Context, o *Options) (*Model, error) {
-	// Open PostgreSQL connection
-	db, err := sql.Open("postgres", o.PostgresURL)
+	if o.MaxIdleConns > 0 {
+		o.Log.Warn("MaxIdleConns is deprecated and ignored, use ConnMaxIdleTime or MinIdleConns", logger.H{
+			"max_idle_conns": o.MaxIdleConns,
+		})
+	}
+
+	// Open PostgreSQL pool
+	db, err := newPool(ctx, o.PostgresURL, o)
 	if err != nil {
-		o.Log.Error("Failed to open PostgreSQL connection", logger.H{"error": err})
-		return nil, fmt.Errorf("failed to open PostgreSQL connection: %w", err)
+		o.Log.Error("Failed to open PostgreSQL pool", logger.H{"error": err})
+		return nil, err
+	}
+
+	// Test connection, unless connecting lazily on first use
+	if !o.LazyConnect {
+		if err := waitForPostgres(ctx, db, o); err != nil {
+			o.Log.Error("[PKG DB] Failed to ping PostgreSQL.", map[string]any{
+				"error": err,
+			})
+			db.Close()
+			return nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
+		}
+	}
+
+	m := &Model{
+		DB: db,
+
+		log:     o.Log,
+		txRetry: newTxRetry(o),
+	}
+
+	// Open read replicas
+	if len(o.ReplicaURLs) > 0 {
+		if err := m.openReplicas(ctx, o); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
+
+	opt := &m_options.Options{
+		Log:    o.Log,
+		DB:     db,
+		Router: m.router,
+		Clock:  o.Clock,
+
+		SoftDelete: o.SoftDelete,
+	}
+
+	m.CurrencyRate = m_currency_rate.New(opt)
+	m.Expense = m_expense.New(opt)
+	m.Income = m_income.New(opt)
+
+	// Detect drift between the facades and the live schema
+	if o.SchemaCheck != SchemaCheckOff {
+		if err := m.checkSchema(ctx, o.SchemaCheck); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// newPool builds a pgx pool for url with the pool settings from o.
+func newPool(ctx context.Context, url string, o *Options) (*pgxpool.Pool, error) {
+	// Parse PostgreSQL pool config
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PostgreSQL URL: %w", err)
 	}
 
 	// Configure connection pool
 	if o.MaxOpenConns > 0 {
-		db.SetMaxOpenConns(o.MaxOpenConns)
+		cfg.MaxConns = int32(o.MaxOpenConns)
 	} else {
-		db.SetMaxOpenConns(25) // Default
+		cfg.MaxConns = 25 // Default
 	}
 
-	if o.MaxIdleConns > 0 {
-		db.SetMaxIdleConns(o.MaxIdleConns)
-	} else {
-		db.SetMaxIdleConns(5) // Default
+	if o.MinIdleConns > 0 {
+		cfg.MinIdleConns = int32(o.MinIdleConns)
 	}
 
 	if o.ConnMaxLifetime > 0 {
-		db.SetConnMaxLifetime(o.ConnMaxLifetime)
+		cfg.MaxConnLifetime = o.ConnMaxLifetime
 	} else {
-		db.SetConnMaxLifetime(5 * time.Minute) // Default
+		cfg.MaxConnLifetime = 5 * time.Minute // Default
 	}
 
 	if o.ConnMaxIdleTime > 0 {
-		db.SetConnMaxIdleTime(o.ConnMaxIdleTime)
+		cfg.MaxConnIdleTime = o.ConnMaxIdleTime
 	} else {
-		db.SetConnMaxIdleTime(90 * time.Second) // Default
+		cfg.MaxConnIdleTime = 90 * time.Second // Default
 	}
 
-	// Test connection
-	if err := ping(ctx, db); err != nil {
-		o.Log.Error("[PKG DB] Failed to ping PostgreSQL.", map[string]any{
-			"error": err,
-		})
-		db.Close()
-		return nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
-	}
-
-	opt := &m_options.Options{
-		Log: o.Log,
-		DB:  db,
+	db, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PostgreSQL pool: %w", err)
 	}
-
-	return &Model{
-		DB: db,
-		//
-		Expense: m_expense.New(opt),
-		Income:  m_income.New(opt),
-	}, nil
+	return db, nil
 }
 
-func ping(ctx context.Context, db *sql.DB) error {
-	// Use PingContext to verify connection
-	if err := db.PingContext(ctx); err != nil {
+func ping(ctx context.Context, db *pgxpool.Pool) error {
+	// Use Ping to verify connection
+	if err := db.Ping(ctx); err != nil {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	// Additional test query
 	var testResult int
-	err := db.QueryRowContext(ctx, "SELECT 1").Scan(&testResult)
+	err := db.QueryRow(ctx, "SELECT 1").Scan(&testResult)
 	if err != nil {
 		return fmt.Errorf("failed to execute test query: %w", err)
 	}
@@ -108,20 +195,26 @@ func ping(ctx context.Context, db *sql.DB) error {
 	return nil
 }
 
-// Close closes the database connection
+// Close closes the database connection pools
 func (m *Model) Close() error {
+	if m.stopMonitor != nil {
+		m.stopMonitor()
+	}
+	for _, replica := range m.replicas {
+		replica.Close()
+	}
 	if m.DB != nil {
-		return m.DB.Close()
+		m.DB.Close()
 	}
 	return nil
 }
 
-// BeginTx starts a new transaction
-func (m *Model) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+// BeginTx starts a new transaction shared by every facade
+func (m *Model) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
 	return m.DB.BeginTx(ctx, opts)
 }
 
 // Begin starts a new transaction with default options
-func (m *Model) Begin() (*sql.Tx, error) {
-	return m.DB.Begin()
+func (m *Model) Begin(ctx context.Context) (pgx.Tx, error) {
+	return m.DB.Begin(ctx)
 }
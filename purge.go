@@ -0,0 +1,37 @@
+package db_fd_model
+
+import (
+	"context"
+	"time"
+
+	"github.com/rsmrtk/db-fd-model/m_expense"
+	"github.com/rsmrtk/db-fd-model/m_income"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// Purge permanently removes incomes and expenses soft-deleted more than
+// olderThan ago and returns the number of rows removed. Run it from a
+// periodic job to enforce the retention window.
+func (m *Model) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	purges := []struct {
+		table string
+		purge func(context.Context, time.Duration) (int64, error)
+	}{
+		{m_income.Table, m.Income.Purge},
+		{m_expense.Table, m.Expense.Purge},
+	}
+
+	var total int64
+	for _, p := range purges {
+		n, err := p.purge(ctx, olderThan)
+		if err != nil {
+			m.log.Error("[PKG DB] Failed to purge soft-deleted rows.", logger.H{
+				"error": err,
+				"table": p.table,
+			})
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
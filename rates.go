@@ -0,0 +1,23 @@
+package db_fd_model
+
+import (
+	"context"
+
+	"github.com/rsmrtk/db-fd-model/m_rate_import"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// ImportRates upserts the exchange rates in a local ECB XML or NBU JSON/CSV
+// file, detecting the format from its extension. It needs no network access,
+// so it can run from a nightly batch against downloaded files.
+func (m *Model) ImportRates(ctx context.Context, path string) (int, error) {
+	n, err := m_rate_import.ImportFile(ctx, m.CurrencyRate, path, "")
+	if err != nil {
+		m.log.Error("[PKG DB] Failed to import currency rates.", logger.H{
+			"error": err,
+			"path":  path,
+		})
+		return 0, err
+	}
+	return n, nil
+}
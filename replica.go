@@ -0,0 +1,64 @@
+package db_fd_model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rsmrtk/db-fd-model/m_options"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// openReplicas opens a pool per replica URL and builds the read router. For
+// the lag-aware policy it also starts a monitor that refreshes replica lag.
+func (m *Model) openReplicas(ctx context.Context, o *Options) error {
+	for i, url := range o.ReplicaURLs {
+		replica, err := newPool(ctx, url, o)
+		if err != nil {
+			o.Log.Error("Failed to open PostgreSQL replica pool", logger.H{"error": err, "replica": i})
+			return fmt.Errorf("replica %d: %w", i, err)
+		}
+		m.replicas = append(m.replicas, replica)
+
+		if o.LazyConnect {
+			continue
+		}
+		if err := waitForPostgres(ctx, replica, o); err != nil {
+			o.Log.Error("[PKG DB] Failed to ping PostgreSQL replica.", logger.H{"error": err, "replica": i})
+			return fmt.Errorf("failed to ping PostgreSQL replica %d: %w", i, err)
+		}
+	}
+
+	m.router = m_options.NewRouter(m.DB, m.replicas, o.ReplicaPolicy, o.ReplicaMaxLag)
+
+	if o.ReplicaPolicy == m_options.PolicyLagAware {
+		interval := o.ReplicaCheckInterval
+		if interval <= 0 {
+			interval = 5 * time.Second // Default
+		}
+
+		m.router.RefreshLag(ctx)
+
+		monitorCtx, cancel := context.WithCancel(context.Background())
+		m.stopMonitor = cancel
+		go m.monitorReplicas(monitorCtx, interval)
+	}
+
+	return nil
+}
+
+func (m *Model) monitorReplicas(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			m.router.RefreshLag(checkCtx)
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package db_fd_model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rsmrtk/db-fd-model/m_audit"
+	"github.com/rsmrtk/db-fd-model/m_currency_rate"
+	"github.com/rsmrtk/db-fd-model/m_expense"
+	"github.com/rsmrtk/db-fd-model/m_income"
+	"github.com/rsmrtk/db-fd-model/m_schema"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// SchemaCheckMode controls what New does when the facades do not match the
+// live schema.
+type SchemaCheckMode string
+
+const (
+	SchemaCheckOff  SchemaCheckMode = ""     // Do not check
+	SchemaCheckWarn SchemaCheckMode = "warn" // Log every difference at Warn
+	SchemaCheckFail SchemaCheckMode = "fail" // Log every difference at Error and fail New
+)
+
+// ErrSchemaDrift is returned by New in SchemaCheckFail mode when the
+// facades do not match the live schema.
+var ErrSchemaDrift = errors.New("schema drift detected")
+
+// schemas lists every table described by a facade.
+func schemas() []m_schema.Table {
+	return []m_schema.Table{
+		m_income.GetSchema(),
+		m_expense.GetSchema(),
+		m_currency_rate.GetSchema(),
+		m_audit.GetSchema(),
+	}
+}
+
+// CheckSchema compares the facades' columns, Go types, nullability and enum
+// values against the live schema and returns every difference.
+func (m *Model) CheckSchema(ctx context.Context) ([]m_schema.Diff, error) {
+	return m_schema.Check(ctx, m.DB, schemas()...)
+}
+
+// checkSchema reports drift at Warn in SchemaCheckWarn mode and never fails
+// New; only SchemaCheckFail logs at Error and returns an error.
+func (m *Model) checkSchema(ctx context.Context, mode SchemaCheckMode) error {
+	log := m.log.Warn
+	if mode == SchemaCheckFail {
+		log = m.log.Error
+	}
+
+	diffs, err := m.CheckSchema(ctx)
+	if err != nil {
+		if mode != SchemaCheckFail {
+			log("[PKG DB] Failed to check schema.", logger.H{
+				"mode":  string(mode),
+				"error": err,
+			})
+			return nil
+		}
+		return fmt.Errorf("failed to check schema: %w", err)
+	}
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(diffs))
+	for i, d := range diffs {
+		lines[i] = d.String()
+	}
+	log("[PKG DB] Schema drift detected.", logger.H{
+		"mode":  string(mode),
+		"diffs": lines,
+	})
+
+	if mode == SchemaCheckFail {
+		return fmt.Errorf("%w: %s", ErrSchemaDrift, strings.Join(lines, "; "))
+	}
+	return nil
+}
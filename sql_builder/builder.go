@@ -8,6 +8,7 @@ import (
 // Builder is the main struct for constructing SQL queries.
 // All methods for building clauses are directly on this struct.
 type Builder[FieldType ~string] struct {
+	initial     string // statement head used when Select and From are not called
 	params      map[string]any
 	paramWriter *strings.Builder
 
@@ -32,9 +33,11 @@ type AfterWhere[FieldType ~string] struct {
 }
 
 // New creates a new instance of the Builder.
-// The 'initial' string parameter is currently unused.
+// The 'initial' string, e.g. "SELECT COUNT(*) FROM incomes", starts the
+// query unless Select or From are called.
 func New[FieldType ~string](initial string) *Builder[FieldType] {
 	sqlb := &Builder[FieldType]{
+		initial:     initial,
 		params:      make(map[string]any),
 		paramWriter: &strings.Builder{},
 
@@ -402,9 +405,28 @@ func (b *Builder[FieldType]) Offset(val int) *Builder[FieldType] {
 }
 
 func (b *Builder[FieldType]) String() string {
-	return b.selectClause.String() +
-		b.fromClause.String() +
-		b.whereClause.String() +
+	return b.render(nil)
+}
+
+// render builds the query with scope ANDed into the WHERE clause.
+func (b *Builder[FieldType]) render(scope []string) string {
+	head := b.selectClause.String() + b.fromClause.String()
+	if head == "" {
+		head = b.initial
+	}
+
+	where := b.whereClause.String()
+	if len(scope) > 0 {
+		conditions := strings.Join(scope, " AND ")
+		if where == "" {
+			where = " WHERE " + conditions
+		} else {
+			where = " WHERE (" + strings.TrimPrefix(where, " WHERE ") + ") AND " + conditions
+		}
+	}
+
+	return head +
+		where +
 		b.groupByClause.String() +
 		b.orderByClause.String() +
 		b.limitClause.String() +
@@ -424,10 +446,20 @@ func (b *Builder[FieldType]) Fields() []FieldType {
 
 // StringPostgres returns the SQL query string with PostgreSQL-style placeholders ($1, $2, etc.)
 func (b *Builder[FieldType]) StringPostgres() string {
-	queryStr := b.String()
+	return b.postgres(b.render(nil))
+}
 
-	// Replace @paramN with $N for PostgreSQL
-	for i := 0; i < len(b.params); i++ {
+// StringPostgresScoped is like StringPostgres but ANDs scope into the WHERE
+// clause, e.g. the "deleted_at IS NULL" a facade applies to every read. The
+// builder itself is left unchanged.
+func (b *Builder[FieldType]) StringPostgresScoped(scope ...string) string {
+	return b.postgres(b.render(scope))
+}
+
+func (b *Builder[FieldType]) postgres(queryStr string) string {
+	// Replace @paramN with $N for PostgreSQL, highest first so @param1
+	// does not match the start of @param10
+	for i := len(b.params) - 1; i >= 0; i-- {
 		old := "@param" + strconv.Itoa(i)
 		new := "$" + strconv.Itoa(i+1)
 		queryStr = strings.Replace(queryStr, old, new, -1)
@@ -0,0 +1,87 @@
+package sql_builder
+
+import "strconv"
+
+// Expr is an UPDATE value computed by the database, usually from the
+// current value of the column, so the change needs no read-modify-write.
+// Use it as a value in a facade's UpdateFields.
+type Expr struct {
+	render func(column string, arg func(value any) string) string
+
+	// value is what Coalesce and IfNull may store, checked before the write
+	value    any
+	hasValue bool
+}
+
+// Value returns the literal the column may be set to, for Coalesce and
+// IfNull, so it can be validated like a plain value. Arithmetic and
+// DEFAULT have no such value.
+func (e Expr) Value() (any, bool) {
+	return e.value, e.hasValue
+}
+
+// Incr adds delta to the column: column = column + delta.
+func Incr(delta any) Expr {
+	return Expr{render: func(column string, arg func(any) string) string {
+		return column + " + " + arg(delta)
+	}}
+}
+
+// Decr subtracts delta from the column: column = column - delta.
+func Decr(delta any) Expr {
+	return Expr{render: func(column string, arg func(any) string) string {
+		return column + " - " + arg(delta)
+	}}
+}
+
+// Default resets the column to its DEFAULT, or NULL when it has none.
+func Default() Expr {
+	return Expr{render: func(string, func(any) string) string {
+		return "DEFAULT"
+	}}
+}
+
+// Now sets the column to the transaction start time.
+func Now() Expr {
+	return Expr{render: func(string, func(any) string) string {
+		return "NOW()"
+	}}
+}
+
+// Coalesce sets the column to value unless value is NULL, in which case the
+// column keeps its current value: column = COALESCE(value, column).
+func Coalesce(value any) Expr {
+	return Expr{
+		render: func(column string, arg func(any) string) string {
+			return "COALESCE(" + arg(value) + ", " + column + ")"
+		},
+		value:    value,
+		hasValue: true,
+	}
+}
+
+// IfNull sets the column to value only while it is NULL:
+// column = COALESCE(column, value).
+func IfNull(value any) Expr {
+	return Expr{
+		render: func(column string, arg func(any) string) string {
+			return "COALESCE(" + column + ", " + arg(value) + ")"
+		},
+		value:    value,
+		hasValue: true,
+	}
+}
+
+// SetClause renders "column = value" for an UPDATE and appends the
+// arguments it needs to args, numbering placeholders after them. value is
+// sent as a parameter unless it is an Expr.
+func SetClause(column string, value any, args []any) (string, []any) {
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if expr, ok := value.(Expr); ok && expr.render != nil {
+		return column + " = " + expr.render(column, arg), args
+	}
+	return column + " = " + arg(value), args
+}
@@ -0,0 +1,68 @@
+package db_fd_model
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// waitForPostgres pings db until it answers. With StartupMaxWait unset it
+// makes a single attempt; otherwise it retries with exponential backoff and
+// equal jitter (half the backoff plus a random share of the other half)
+// until the wait is exhausted or ctx is done. Retries are logged at Warn;
+// the final failure is returned to New, which logs it at Error.
+func waitForPostgres(ctx context.Context, db *pgxpool.Pool, o *Options) error {
+	if o.StartupMaxWait <= 0 {
+		return ping(ctx, db)
+	}
+
+	backoff := o.StartupBackoff
+	if backoff <= 0 {
+		backoff = 200 * time.Millisecond // Default
+	}
+	maxBackoff := o.StartupMaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 5 * time.Second // Default
+	}
+
+	deadline := time.Now().Add(o.StartupMaxWait)
+	for attempt := 1; ; attempt++ {
+		err := ping(ctx, db)
+		if err == nil {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("PostgreSQL not ready after %s (%d attempts): %w", o.StartupMaxWait, attempt, err)
+		}
+
+		sleep := backoff/2 + rand.N(backoff/2+1)
+		if sleep > remaining {
+			sleep = remaining
+		}
+
+		o.Log.Warn("[PKG DB] PostgreSQL not ready, retrying.", logger.H{
+			"error":   err,
+			"attempt": attempt,
+			"retry":   sleep.String(),
+		})
+
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("waiting for PostgreSQL: %w", ctx.Err())
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package db_fd_model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// The startup tests point the model at a local port where PostgreSQL is not
+// listening yet. Tests that need a real server read POSTGRES_URL and are
+// skipped when it is unset; the port is then opened late by a TCP proxy to
+// that server, as if PostgreSQL had started after the model.
+
+func testLogger() *logger.Logger {
+	return &logger.Logger{}
+}
+
+// postgresURL returns POSTGRES_URL or skips the test.
+func postgresURL(t *testing.T) *url.URL {
+	t.Helper()
+	raw := os.Getenv("POSTGRES_URL")
+	if raw == "" {
+		t.Skip("POSTGRES_URL is not set")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("invalid POSTGRES_URL: %v", err)
+	}
+	return u
+}
+
+// closedAddr returns a local address nothing listens on.
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+	return addr
+}
+
+// withHost returns u pointing at addr.
+func withHost(u *url.URL, addr string) string {
+	c := *u
+	c.Host = addr
+	return c.String()
+}
+
+// startProxy opens addr and forwards every connection to target until the
+// test ends.
+func startProxy(t *testing.T, addr, target string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		_ = l.Close()
+		wg.Wait()
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			client, err := l.Accept()
+			if err != nil {
+				return
+			}
+			server, err := net.Dial("tcp", target)
+			if err != nil {
+				_ = client.Close()
+				continue
+			}
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_, _ = io.Copy(server, client)
+				_ = server.Close()
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = io.Copy(client, server)
+				_ = client.Close()
+			}()
+		}
+	}()
+	return nil
+}
+
+func TestStartupRetriesUntilPostgresIsUp(t *testing.T) {
+	pg := postgresURL(t)
+	addr := closedAddr(t)
+
+	// PostgreSQL "starts" a second after the model
+	proxyErr := make(chan error, 1)
+	go func() {
+		time.Sleep(time.Second)
+		proxyErr <- startProxy(t, addr, pg.Host)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	m, err := New(ctx, &Options{
+		PostgresURL:       withHost(pg, addr),
+		Log:               testLogger(),
+		StartupMaxWait:    15 * time.Second,
+		StartupBackoff:    50 * time.Millisecond,
+		StartupMaxBackoff: 200 * time.Millisecond,
+	})
+	if err := <-proxyErr; err != nil {
+		t.Fatalf("failed to start proxy: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("New failed although PostgreSQL came up within StartupMaxWait: %v", err)
+	}
+	defer m.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("New returned after %s, before PostgreSQL was up", elapsed)
+	}
+}
+
+func TestStartupMaxWaitExceeded(t *testing.T) {
+	addr := closedAddr(t)
+
+	start := time.Now()
+	_, err := New(context.Background(), &Options{
+		PostgresURL:       "postgres://user:password@" + addr + "/db?sslmode=disable",
+		Log:               testLogger(),
+		StartupMaxWait:    500 * time.Millisecond,
+		StartupBackoff:    50 * time.Millisecond,
+		StartupMaxBackoff: 100 * time.Millisecond,
+	})
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("New succeeded without PostgreSQL")
+	}
+	if !strings.Contains(err.Error(), "not ready after") {
+		t.Fatalf("error does not report the exhausted wait: %v", err)
+	}
+	if elapsed < 500*time.Millisecond {
+		t.Fatalf("New gave up after %s, before StartupMaxWait", elapsed)
+	}
+	if elapsed > 5*time.Second {
+		t.Fatalf("New kept retrying for %s, well past StartupMaxWait", elapsed)
+	}
+}
+
+func TestStartupHonoursContext(t *testing.T) {
+	addr := closedAddr(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err := New(ctx, &Options{
+		PostgresURL:    "postgres://user:password@" + addr + "/db?sslmode=disable",
+		Log:            testLogger(),
+		StartupMaxWait: time.Minute,
+		StartupBackoff: 50 * time.Millisecond,
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the context deadline, got %v", err)
+	}
+}
+
+func TestLazyConnect(t *testing.T) {
+	addr := closedAddr(t)
+	pg, _ := url.Parse("postgres://user:password@" + addr + "/db?sslmode=disable")
+	if os.Getenv("POSTGRES_URL") != "" {
+		pg = postgresURL(t)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m, err := New(ctx, &Options{
+		PostgresURL: withHost(pg, addr),
+		Log:         testLogger(),
+		LazyConnect: true,
+	})
+	if err != nil {
+		t.Fatalf("New with LazyConnect failed without PostgreSQL: %v", err)
+	}
+	defer m.Close()
+
+	if err := m.DB.Ping(ctx); err == nil {
+		t.Fatal("Ping succeeded before PostgreSQL was up")
+	}
+
+	// Connect on first use once PostgreSQL is up
+	pg = postgresURL(t)
+	if err := startProxy(t, addr, pg.Host); err != nil {
+		t.Fatalf("failed to start proxy: %v", err)
+	}
+	if err := ping(ctx, m.DB); err != nil {
+		t.Fatalf("first use after PostgreSQL came up failed: %v", err)
+	}
+}
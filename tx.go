@@ -0,0 +1,143 @@
+package db_fd_model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rsmrtk/db-fd-model/m_currency_rate"
+	"github.com/rsmrtk/db-fd-model/m_errors"
+	"github.com/rsmrtk/db-fd-model/m_expense"
+	"github.com/rsmrtk/db-fd-model/m_income"
+	"github.com/rsmrtk/smartlg/logger"
+)
+
+// TxModel exposes every facade bound to a single transaction.
+type TxModel struct {
+	Tx pgx.Tx
+	//
+	CurrencyRate *m_currency_rate.Facade
+	Expense      *m_expense.Facade
+	Income       *m_income.Facade
+
+	m *Model
+}
+
+func (m *Model) newTxModel(tx pgx.Tx) *TxModel {
+	return &TxModel{
+		Tx: tx,
+		//
+		CurrencyRate: m.CurrencyRate.WithExecutor(tx),
+		Expense:      m.Expense.WithExecutor(tx),
+		Income:       m.Income.WithExecutor(tx),
+
+		m: m,
+	}
+}
+
+// WithTx runs fn in a nested unit of work backed by a SAVEPOINT. When fn
+// fails or panics only its changes are rolled back (ROLLBACK TO SAVEPOINT)
+// and the outer transaction can continue; on success the savepoint is
+// released. Nested units are not retried on their own: a serialization
+// failure aborts the outer transaction, which Model.WithTx retries.
+func (t *TxModel) WithTx(ctx context.Context, fn func(tx *TxModel) error) error {
+	sp, err := t.Tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	return t.m.run(ctx, sp, fn)
+}
+
+// WithTx runs fn inside a transaction. The transaction is committed when fn
+// returns nil and rolled back when it returns an error or panics. The whole
+// function is retried on serialization failures and deadlocks, so fn must be
+// safe to run more than once.
+func (m *Model) WithTx(ctx context.Context, opts pgx.TxOptions, fn func(tx *TxModel) error) error {
+	backoff := m.txRetry.backoff
+	for attempt := 1; ; attempt++ {
+		err := m.runTx(ctx, opts, fn)
+		if err == nil || !m_errors.IsRetryable(err) || attempt >= m.txRetry.maxAttempts {
+			return err
+		}
+
+		m.log.Warn("[PKG DB] Retrying transaction.", logger.H{
+			"error":   err,
+			"attempt": attempt,
+			"backoff": backoff.String(),
+		})
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("transaction retry aborted: %w", errors.Join(ctx.Err(), err))
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > m.txRetry.maxBackoff {
+			backoff = m.txRetry.maxBackoff
+		}
+	}
+}
+
+func (m *Model) runTx(ctx context.Context, opts pgx.TxOptions, fn func(tx *TxModel) error) error {
+	tx, err := m.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return m.run(ctx, tx, fn)
+}
+
+// run calls fn with tx and then commits it, or rolls it back when fn fails
+// or panics. tx may be a transaction or a savepoint.
+func (m *Model) run(ctx context.Context, tx pgx.Tx, fn func(tx *TxModel) error) error {
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+	}()
+
+	if err := fn(m.newTxModel(tx)); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return errors.Join(err, fmt.Errorf("failed to rollback transaction: %w", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+type txRetry struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+func newTxRetry(o *Options) txRetry {
+	r := txRetry{
+		maxAttempts: 3,                     // Default
+		backoff:     50 * time.Millisecond, // Default
+		maxBackoff:  time.Second,           // Default
+	}
+	if o.TxMaxAttempts > 0 {
+		r.maxAttempts = o.TxMaxAttempts
+	}
+	if o.TxRetryBackoff > 0 {
+		r.backoff = o.TxRetryBackoff
+	}
+	if o.TxRetryMaxBackoff > 0 {
+		r.maxBackoff = o.TxRetryMaxBackoff
+	}
+	if r.maxBackoff < r.backoff {
+		r.maxBackoff = r.backoff
+	}
+	return r
+}